@@ -0,0 +1,58 @@
+package goservices
+
+import "errors"
+
+var (
+	// ErrPermanent is the sentinel error wrapped by errors
+	// created with `Permanent`, and can be checked for with
+	// `errors.Is` to find out if an error is permanent.
+	ErrPermanent = errors.New("permanent error")
+	// ErrRetryable is the sentinel error wrapped by errors
+	// created with `Retryable`, and can be checked for with
+	// `errors.Is` to find out if an error is retryable.
+	ErrRetryable = errors.New("retryable error")
+)
+
+// Permanent marks the error given as permanent, meaning
+// the service crashing with it will never recover, for example
+// due to an invalid configuration or an expired license.
+// A `Restarter` does not restart a service crashing with a
+// permanent error, and forwards the error in its run error
+// channel instead. The error message is left unchanged, and
+// the error can be checked with `errors.Is(err, ErrPermanent)`.
+// Permanent returns nil if the error given is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: ErrPermanent}
+}
+
+// Retryable marks the error given as retryable, meaning
+// the service crashing with it can recover by being restarted.
+// This is useful with a `Restarter` configured to only restart
+// services crashing with retryable errors. The error message is
+// left unchanged, and the error can be checked with
+// `errors.Is(err, ErrRetryable)`.
+// Retryable returns nil if the error given is nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: ErrRetryable}
+}
+
+// classifiedError wraps an error together with a class
+// sentinel error, without changing the error message.
+type classifiedError struct {
+	err   error
+	class error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.err, e.class}
+}
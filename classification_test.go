@@ -0,0 +1,108 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Permanent_Retryable(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	assert.NoError(t, Permanent(nil))
+	assert.NoError(t, Retryable(nil))
+
+	err := Permanent(errTest)
+	assert.EqualError(t, err, "test error")
+	assert.ErrorIs(t, err, errTest)
+	assert.ErrorIs(t, err, ErrPermanent)
+	assert.NotErrorIs(t, err, ErrRetryable)
+
+	err = Retryable(errTest)
+	assert.EqualError(t, err, "test error")
+	assert.ErrorIs(t, err, errTest)
+	assert.ErrorIs(t, err, ErrRetryable)
+	assert.NotErrorIs(t, err, ErrPermanent)
+}
+
+func Test_Restarter_shouldRestart(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	testCases := map[string]struct {
+		onlyRetryable bool
+		crashErr      error
+		restart       bool
+	}{
+		"unclassified error": {
+			crashErr: errTest,
+			restart:  true,
+		},
+		"permanent error": {
+			crashErr: Permanent(errTest),
+		},
+		"retryable error": {
+			crashErr: Retryable(errTest),
+			restart:  true,
+		},
+		"only retryable with unclassified error": {
+			onlyRetryable: true,
+			crashErr:      errTest,
+		},
+		"only retryable with retryable error": {
+			onlyRetryable: true,
+			crashErr:      Retryable(errTest),
+			restart:       true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			restarter := &Restarter{onlyRetryable: testCase.onlyRetryable}
+
+			restart := restarter.shouldRestart(testCase.crashErr)
+
+			assert.Equal(t, testCase.restart, restart)
+		})
+	}
+}
+
+func Test_Restarter_permanentError(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("license expired")
+	crash := make(chan struct{})
+	starts := 0
+	service := NewRunWrapper("A", func(_ context.Context,
+		ready chan<- struct{}, runError, _ chan<- error) {
+		starts++
+		close(ready)
+		<-crash
+		runError <- Permanent(errTest)
+		close(runError)
+	})
+
+	restarter, err := NewRestarter(RestarterSettings{Service: service})
+	require.NoError(t, err)
+
+	runError, err := restarter.Start(context.Background())
+	require.NoError(t, err)
+
+	close(crash)
+	err = <-runError
+	assert.ErrorIs(t, err, ErrPermanent)
+	assert.ErrorIs(t, err, errTest)
+	assert.EqualError(t, err, "license expired")
+	assert.Equal(t, 1, starts)
+
+	err = restarter.Stop()
+	assert.NoError(t, err)
+}
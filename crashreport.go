@@ -0,0 +1,212 @@
+package goservices
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CrashReport contains information about a service crash,
+// captured at the time of the crash.
+type CrashReport struct {
+	// Service is the service path, for example "sequence s/group g/A".
+	Service string `json:"service"`
+	// Time is the time at which the crash was caught.
+	Time time.Time `json:"time"`
+	// Error is the crash error message.
+	Error string `json:"error"`
+	// ErrorChain contains the messages of the crash error
+	// and of each of the errors it wraps, depth first.
+	ErrorChain []string `json:"error_chain"`
+	// Uptime is the duration the service was running for
+	// before crashing, in nanoseconds when JSON encoded.
+	Uptime time.Duration `json:"uptime_ns"`
+	// Restarts is the number of times the service was
+	// restarted before this crash, which is only non-zero
+	// for a crash caught by a `Restarter`.
+	Restarts uint `json:"restarts"`
+	// Stacks is the snapshot of the goroutines stacks labeled for
+	// the service, or of all goroutines if none is labeled.
+	Stacks string `json:"stacks"`
+	// File is the path of the JSON file the report was written to,
+	// and is empty if no crash directory is set.
+	File string `json:"-"`
+}
+
+// HooksCrashReport is an optional interface the hooks given
+// to a service management type can implement, to receive
+// crash reports if crash reports are enabled.
+type HooksCrashReport interface {
+	// OnCrashReport is called after OnCrash with the crash report
+	// of the service. The `err` argument is non-nil if the crash
+	// report could not be written to the crash directory.
+	OnCrashReport(service string, report CrashReport, err error)
+}
+
+// CrashReportSettings contains settings for crash reports.
+type CrashReportSettings struct {
+	// Directory is the directory to write crash reports to as
+	// JSON files. It defaults to the empty string, meaning crash
+	// reports are not written to files.
+	Directory string
+	// MaxReports is the maximum number of most recent crash
+	// reports kept in memory. It defaults to 10.
+	MaxReports uint
+}
+
+func (c *CrashReportSettings) setDefaults() {
+	if c.MaxReports == 0 {
+		const defaultMaxReports = 10
+		c.MaxReports = defaultMaxReports
+	}
+}
+
+// crashReporter captures and keeps crash reports.
+// A nil crash reporter does nothing.
+type crashReporter struct {
+	directory  string
+	maxReports uint
+	timeNow    func() time.Time
+
+	mutex      sync.Mutex
+	startTimes map[string]time.Time
+	reports    []CrashReport
+}
+
+func newCrashReporter(settings *CrashReportSettings) *crashReporter {
+	if settings == nil {
+		return nil
+	}
+	return &crashReporter{
+		directory:  settings.Directory,
+		maxReports: settings.MaxReports,
+		timeNow:    time.Now,
+		startTimes: make(map[string]time.Time),
+	}
+}
+
+// started records the service given started now.
+func (c *crashReporter) started(service string) {
+	if c == nil {
+		return
+	}
+	now := c.timeNow()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.startTimes[service] = now
+}
+
+// report captures a crash report for the service given, keeps it,
+// writes it to the crash directory if set, and passes it to the
+// hooks if they implement the HooksCrashReport interface.
+func (c *crashReporter) report(hooks Hooks, path, service string,
+	crashErr error, restarts uint) {
+	if c == nil {
+		return
+	}
+
+	now := c.timeNow()
+	report := CrashReport{
+		Service:    path,
+		Time:       now,
+		Error:      crashErr.Error(),
+		ErrorChain: errorChain(crashErr),
+		Restarts:   restarts,
+		Stacks:     string(goroutinesDump(path)),
+	}
+
+	c.mutex.Lock()
+	startTime, ok := c.startTimes[service]
+	if ok {
+		report.Uptime = now.Sub(startTime)
+	}
+	c.mutex.Unlock()
+
+	var err error
+	if c.directory != "" {
+		report.File, err = writeCrashReport(c.directory, report)
+	}
+
+	c.mutex.Lock()
+	c.reports = append(c.reports, report)
+	if uint(len(c.reports)) > c.maxReports {
+		c.reports = c.reports[uint(len(c.reports))-c.maxReports:]
+	}
+	c.mutex.Unlock()
+
+	reportHooks, ok := hooks.(HooksCrashReport)
+	if ok {
+		reportHooks.OnCrashReport(service, report, err)
+	}
+}
+
+// list returns a copy of the crash reports kept,
+// from the oldest to the most recent one.
+func (c *crashReporter) list() (reports []CrashReport) {
+	if c == nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	reports = make([]CrashReport, len(c.reports))
+	copy(reports, c.reports)
+	return reports
+}
+
+// errorChain returns the messages of the error given
+// and of all the errors it wraps, depth first.
+func errorChain(err error) (messages []string) {
+	if err == nil {
+		return nil
+	}
+	messages = []string{err.Error()}
+	switch wrapper := err.(type) { //nolint:errorlint
+	case interface{ Unwrap() error }:
+		messages = append(messages, errorChain(wrapper.Unwrap())...)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range wrapper.Unwrap() {
+			messages = append(messages, errorChain(wrapped)...)
+		}
+	}
+	return messages
+}
+
+func writeCrashReport(directory string, report CrashReport) (
+	path string, err error) {
+	const directoryPerm = 0o700
+	err = os.MkdirAll(directory, directoryPerm)
+	if err != nil {
+		return "", fmt.Errorf("creating crash directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding crash report: %w", err)
+	}
+
+	filename := "crash-" + report.Time.UTC().Format("20060102T150405.000000000Z") +
+		"-" + sanitizeFilename(report.Service) + ".json"
+	path = filepath.Join(directory, filename)
+	const filePerm = 0o600
+	err = os.WriteFile(path, data, filePerm)
+	if err != nil {
+		return "", fmt.Errorf("writing crash report: %w", err)
+	}
+	return path, nil
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
@@ -0,0 +1,155 @@
+package goservices
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices/hooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_errorChain(t *testing.T) {
+	t.Parallel()
+
+	errA := errors.New("a")
+	errB := errors.New("b")
+
+	testCases := map[string]struct {
+		err      error
+		messages []string
+	}{
+		"nil error": {},
+		"single error": {
+			err:      errA,
+			messages: []string{"a"},
+		},
+		"wrapped errors": {
+			err:      fmt.Errorf("c: %w", errors.Join(errA, fmt.Errorf("d: %w", errB))),
+			messages: []string{"c: a\nd: b", "a\nd: b", "a", "d: b", "b"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			messages := errorChain(testCase.err)
+
+			assert.Equal(t, testCase.messages, messages)
+		})
+	}
+}
+
+type crashReportHooks struct {
+	hooks.NoopHooks
+	reports chan<- CrashReport
+}
+
+func (h *crashReportHooks) OnCrashReport(_ string, report CrashReport, err error) {
+	if err != nil {
+		panic(err)
+	}
+	h.reports <- report
+}
+
+func Test_Group_crashReport(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	crash := make(chan struct{})
+	service := NewRunWrapper("A", func(_ context.Context,
+		ready chan<- struct{}, runError, _ chan<- error) {
+		close(ready)
+		<-crash
+		runError <- fmt.Errorf("wrapped: %w", errTest)
+		close(runError)
+	})
+
+	crashDirectory := t.TempDir()
+	reports := make(chan CrashReport, 1)
+	group, err := NewGroup(GroupSettings{
+		Name:     "g",
+		Services: []Service{service},
+		Hooks:    &crashReportHooks{reports: reports},
+		CrashReports: &CrashReportSettings{
+			Directory: crashDirectory,
+		},
+	})
+	require.NoError(t, err)
+
+	runError, err := group.Start(context.Background())
+	require.NoError(t, err)
+	const uptime = 10 * time.Millisecond
+	time.Sleep(uptime)
+	close(crash)
+	<-runError
+
+	report := <-reports
+	assert.Equal(t, "group g/A", report.Service)
+	assert.Equal(t, "wrapped: test error", report.Error)
+	assert.Equal(t, []string{"wrapped: test error", "test error"}, report.ErrorChain)
+	assert.GreaterOrEqual(t, report.Uptime, uptime)
+	assert.Zero(t, report.Restarts)
+	assert.NotEmpty(t, report.Stacks)
+	assert.Equal(t, crashDirectory, filepath.Dir(report.File))
+	assert.Equal(t, []CrashReport{report}, group.CrashReports())
+
+	data, err := os.ReadFile(report.File)
+	require.NoError(t, err)
+	var fileReport CrashReport
+	err = json.Unmarshal(data, &fileReport)
+	require.NoError(t, err)
+	fileReport.File = report.File
+	assert.True(t, report.Time.Equal(fileReport.Time))
+	fileReport.Time = report.Time
+	assert.Equal(t, report, fileReport)
+}
+
+func Test_Restarter_crashReport(t *testing.T) {
+	t.Parallel()
+
+	crashes := make(chan struct{})
+	service := NewRunWrapper("A", func(ctx context.Context,
+		ready chan<- struct{}, runError, stopError chan<- error) {
+		close(ready)
+		select {
+		case <-ctx.Done():
+			close(stopError)
+		case <-crashes:
+			runError <- errors.New("test error")
+			close(runError)
+		}
+	})
+
+	reports := make(chan CrashReport)
+	restarter, err := NewRestarter(RestarterSettings{
+		Service:      service,
+		Hooks:        &crashReportHooks{reports: reports},
+		CrashReports: &CrashReportSettings{MaxReports: 1},
+	})
+	require.NoError(t, err)
+
+	_, err = restarter.Start(context.Background())
+	require.NoError(t, err)
+
+	crashes <- struct{}{}
+	report := <-reports
+	assert.Equal(t, "A", report.Service)
+	assert.Zero(t, report.Restarts)
+	assert.Empty(t, report.File)
+
+	crashes <- struct{}{}
+	report = <-reports
+	assert.Equal(t, uint(1), report.Restarts)
+	assert.Equal(t, []CrashReport{report}, restarter.CrashReports())
+
+	err = restarter.Stop()
+	require.NoError(t, err)
+}
@@ -0,0 +1,89 @@
+package goservices
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Drainer is an optional interface a service can implement to be
+// notified of the drain phase preceding the stop of a `Sequence` or
+// `Group` with a drain delay set, for example to stop accepting new
+// long-lived connections or to finish in-flight work.
+type Drainer interface {
+	// Drain is called when the drain phase starts, and should
+	// return once the service is drained, or promptly once the
+	// context is canceled at the end of the drain phase.
+	Drain(ctx context.Context)
+}
+
+// treeDrainer is implemented by service management types and
+// wrappers, to propagate the drain phase to the services below them
+// and report how many services implementing `Drainer` were notified.
+type treeDrainer interface {
+	drain(ctx context.Context) (drainers int)
+}
+
+// Drain forwards the drain phase to the wrapped service.
+func (f forwarder) Drain(ctx context.Context) {
+	_ = drainService(ctx, f.service)
+}
+
+func (f forwarder) drain(ctx context.Context) (drainers int) {
+	return drainService(ctx, f.service)
+}
+
+// drainService notifies the service of the drain phase and
+// returns the number of drainers notified, once they are all
+// drained or the context is canceled.
+func drainService(ctx context.Context, service Service) (drainers int) {
+	switch drainer := service.(type) {
+	case treeDrainer:
+		return drainer.drain(ctx)
+	case Drainer:
+		drainer.Drain(ctx)
+		return 1
+	default:
+		return 0
+	}
+}
+
+// drainServices notifies all the services given of the drain phase
+// in parallel, and returns the number of drainers notified once they
+// are all drained, or as soon as the context is canceled.
+func drainServices(ctx context.Context, services []Service) (drainers int) {
+	var count atomic.Int64
+	var waitGroup sync.WaitGroup
+	for _, service := range services {
+		waitGroup.Go(func() {
+			count.Add(int64(drainService(ctx, service)))
+		})
+	}
+
+	done := make(chan struct{})
+	go func() {
+		waitGroup.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// Drainers are expected to return promptly once the
+		// context is canceled, so do not wait for them.
+	}
+	return int(count.Load())
+}
+
+// runDrainPhase runs the drain phase of the drainer given for at most
+// the delay given. If no drainer is notified, the full delay is waited,
+// for example for a load balancer to notice the readiness change.
+func runDrainPhase(drainer treeDrainer, delay time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), delay)
+	defer cancel()
+	drainers := drainer.drain(ctx)
+	if drainers == 0 {
+		<-ctx.Done()
+	}
+}
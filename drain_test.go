@@ -0,0 +1,120 @@
+package goservices
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Group_DrainDelay(t *testing.T) {
+	t.Parallel()
+
+	var group *Group
+	var readyErrDuringDrain error
+	serviceA := newTestOptionalService("A")
+	serviceA.onDrain = func() {
+		readyErrDuringDrain = group.Ready()
+	}
+	serviceB := newTestService("B", nil, nil)
+
+	group, err := NewGroup(GroupSettings{
+		Name:       "g",
+		Services:   []Service{serviceA, serviceB},
+		DrainDelay: time.Hour,
+	})
+	require.NoError(t, err)
+
+	_, err = group.Start(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, group.Ready())
+
+	// The drain phase ends as soon as all drainers are drained.
+	err = group.Stop()
+	require.NoError(t, err)
+
+	assert.EqualError(t, readyErrDuringDrain, "not ready: group g is draining")
+	assert.Equal(t, []string{"draining", "drained", "stopped"}, serviceA.getEvents())
+
+	// Readiness is restored on restart.
+	_, err = group.Start(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, group.Ready())
+	err = group.Stop()
+	require.NoError(t, err)
+}
+
+func Test_Sequence_DrainDelay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nested_drainer", func(t *testing.T) {
+		t.Parallel()
+
+		serviceA := newTestOptionalService("A")
+		group, err := NewGroup(GroupSettings{Services: []Service{serviceA}})
+		require.NoError(t, err)
+		sequence, err := NewSequence(SequenceSettings{
+			ServicesStart: []Service{group},
+			ServicesStop:  []Service{group},
+			DrainDelay:    time.Hour,
+		})
+		require.NoError(t, err)
+
+		_, err = sequence.Start(context.Background())
+		require.NoError(t, err)
+		err = sequence.Stop()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"draining", "drained", "stopped"}, serviceA.getEvents())
+	})
+
+	t.Run("no_drainer_waits_delay", func(t *testing.T) {
+		t.Parallel()
+
+		service := newTestService("A", nil, nil)
+		const drainDelay = 20 * time.Millisecond
+		sequence, err := NewSequence(SequenceSettings{
+			ServicesStart: []Service{service},
+			ServicesStop:  []Service{service},
+			DrainDelay:    drainDelay,
+		})
+		require.NoError(t, err)
+
+		_, err = sequence.Start(context.Background())
+		require.NoError(t, err)
+
+		start := time.Now()
+		err = sequence.Stop()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), drainDelay)
+	})
+
+	t.Run("nested_composite_drained_once", func(t *testing.T) {
+		t.Parallel()
+
+		const drainDelay = 100 * time.Millisecond
+		group, err := NewGroup(GroupSettings{
+			Services:   []Service{newTestService("A", nil, nil)},
+			DrainDelay: drainDelay,
+		})
+		require.NoError(t, err)
+		sequence, err := NewSequence(SequenceSettings{
+			ServicesStart: []Service{group},
+			ServicesStop:  []Service{group},
+			DrainDelay:    drainDelay,
+		})
+		require.NoError(t, err)
+
+		_, err = sequence.Start(context.Background())
+		require.NoError(t, err)
+
+		start := time.Now()
+		err = sequence.Stop()
+		require.NoError(t, err)
+		elapsed := time.Since(start)
+		assert.GreaterOrEqual(t, elapsed, drainDelay)
+		assert.Less(t, elapsed, 2*drainDelay)
+	})
+}
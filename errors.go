@@ -3,6 +3,7 @@ package goservices
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 var (
@@ -15,46 +16,180 @@ var (
 	ErrServicesStartStopMismatch = errors.New("services to start and stop mismatch")
 	ErrServicesNotUnique         = errors.New("services are not unique")
 
+	ErrWatchdogSinkIsNil        = errors.New("watchdog sink is nil")
+	ErrWatchdogTimeoutsNotValid = errors.New("watchdog timeouts are not valid")
+
+	ErrHeartbeatTimeoutNotValid = errors.New("heartbeat timeout is not valid")
+	// ErrHeartbeatMissed is the run error wrapped by a `HeartbeatWatchdog`
+	// when its service misses its heartbeat deadline.
+	ErrHeartbeatMissed = errors.New("heartbeat missed")
+	// ErrStopTimeout is wrapped by the run error of a `HeartbeatWatchdog`
+	// when its underlying service does not stop within the heartbeat
+	// timeout after missing a heartbeat.
+	ErrStopTimeout = errors.New("stop timeout exceeded")
+
+	ErrHealthCheckerIsNil     = errors.New("health checker is nil")
+	ErrHealthDurationNotValid = errors.New("health duration is not valid")
+	// ErrUnhealthy is the run error wrapped by a `HealthMonitor`
+	// when its service fails too many consecutive health checks.
+	ErrUnhealthy = errors.New("service is unhealthy")
+
+	ErrReadinessTimeoutNotValid = errors.New("readiness timeout is not valid")
+	// ErrNotReady is the error wrapped by readiness errors of
+	// service management types and by a `Sequence` start error
+	// when a service does not become ready in time.
+	ErrNotReady = errors.New("not ready")
+
+	ErrDrainDelayNotValid = errors.New("drain delay is not valid")
+
+	ErrNewServerIsNil          = errors.New("new server function is nil")
+	ErrServerIsNil             = errors.New("server is nil")
+	ErrServerNoShutdown        = errors.New("server has no shutdown method")
+	ErrShutdownTimeoutNotValid = errors.New("shutdown timeout is not valid")
+	// ErrServeReturned is the run error of a `ServeShutdown` service
+	// when its server `Serve` method returns without error and
+	// without being stopped.
+	ErrServeReturned = errors.New("serve returned")
+	// ErrShutdownTimeout is wrapped by the error returned by `Stop`
+	// of a `ServeShutdown` service when its server does not shut down
+	// within the shutdown timeout and is forcibly closed.
+	ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+
+	ErrNoSignal = errors.New("no signal specified")
+	// ErrSignalReceived is the run error wrapped by services
+	// stopping on an OS signal, such as the signals package service.
+	// A crash wrapping it is considered as a clean exit by `Main`.
+	ErrSignalReceived = errors.New("signal received")
+	// ErrUpgraded is the run error wrapped by services handing
+	// over to an upgraded process, such as the upgrade package service.
+	// A crash wrapping it is considered as a clean exit by `Main`.
+	ErrUpgraded = errors.New("upgraded")
+
 	ErrAlreadyStarted = errors.New("already started")
 	ErrAlreadyStopped = errors.New("already stopped")
 )
 
-const (
-	errorFormatCrash = "%s crashed: %s"
-	errorFormatStart = "starting %s: %s"
-	errorFormatStop  = "stopping %s: %s"
+var (
+	_ error = (*StartError)(nil)
+	_ error = (*CrashError)(nil)
+	_ error = (*StopError)(nil)
+	_ error = (*StopErrors)(nil)
 )
 
-var _ error = serviceError{}
+// StartError is the error of a service failing to start,
+// returned by service management types such as `Sequence`
+// and `Group`. It can be checked for with `errors.As`.
+type StartError struct {
+	// Service is the name of the service which failed to start.
+	Service string
+	// Err is the error returned by the service `Start` method.
+	Err error
+}
+
+func (e *StartError) Error() string {
+	if e.Err == nil {
+		panic("cannot have nil error in StartError")
+	}
+	return "starting " + e.Service + ": " + e.Err.Error()
+}
+
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// CrashError is the error of a service crashing, sent in the
+// run error channel of service management types such as `Sequence`
+// and `Group`. It can be checked for with `errors.As`.
+type CrashError struct {
+	// Service is the name of the service which crashed.
+	Service string
+	// Err is the run error sent by the service.
+	Err error
+}
+
+func (e *CrashError) Error() string {
+	if e.Err == nil {
+		panic("cannot have nil error in CrashError")
+	}
+	return e.Service + " crashed: " + e.Err.Error()
+}
 
-type serviceError struct {
-	format      string
-	serviceName string
-	err         error
+func (e *CrashError) Unwrap() error {
+	return e.Err
 }
 
-func (s serviceError) Error() string {
-	if s.err == nil {
-		panic("cannot have nil error in serviceError")
+// StopError is the error of a service failing to stop.
+// It can be checked for with `errors.As`.
+type StopError struct {
+	// Service is the name of the service which failed to stop.
+	Service string
+	// Err is the error returned by the service `Stop` method.
+	Err error
+}
+
+func (e *StopError) Error() string {
+	if e.Err == nil {
+		panic("cannot have nil error in StopError")
 	}
-	return fmt.Sprintf(s.format, s.serviceName, s.err.Error())
+	stopErrors, ok := e.Err.(*StopErrors) //nolint:errorlint
+	if ok && len(stopErrors.Errors) > 1 {
+		// Delimit the stop errors of a nested service management
+		// type from the stop errors of its siblings.
+		return "stopping " + e.Service + ": (" + e.Err.Error() + ")"
+	}
+	return "stopping " + e.Service + ": " + e.Err.Error()
 }
 
-func (s serviceError) Unwrap() error {
-	return s.err
+func (e *StopError) Unwrap() error {
+	return e.Err
+}
+
+// StopErrors aggregates the stop errors of services stopped
+// by a service management type such as `Sequence` or `Group`,
+// and is returned by their `Stop` method if any service fails
+// to stop. Each stop error can be checked with `errors.Is` and
+// `errors.As`, as for an error created with `errors.Join`.
+type StopErrors struct {
+	// Errors contains the stop error of each service which
+	// failed to stop, in the order they were stopped.
+	Errors []*StopError
+}
+
+func (e *StopErrors) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the stop error of each service
+// which failed to stop.
+func (e *StopErrors) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
 }
 
+// addStopError adds the stop error `newErr` of the service named
+// `serviceName` to the `collected` stop errors, if `newErr` is not nil.
+// The `collected` error must be nil or a `*StopErrors` created by
+// a previous call to addStopError.
 func addStopError(collected error, serviceName string,
 	newErr error) (newCollected error) {
 	if newErr == nil {
 		return collected
 	}
 
-	newErr = fmt.Errorf("stopping %s: %w", serviceName, newErr)
+	stopErr := &StopError{Service: serviceName, Err: newErr}
 	if collected == nil {
-		return newErr
+		return &StopErrors{Errors: []*StopError{stopErr}}
 	}
-	return fmt.Errorf("%w; %w", collected, newErr)
+	stopErrors := collected.(*StopErrors) //nolint:forcetypeassert
+	stopErrors.Errors = append(stopErrors.Errors, stopErr)
+	return stopErrors
 }
 
 // addCtxErrorIfNeeded adds the ctxErr to the serviceErr if
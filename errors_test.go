@@ -7,33 +7,68 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func Test_serviceError(t *testing.T) {
+func Test_serviceErrors(t *testing.T) {
 	t.Parallel()
 	errTest := errors.New("test error")
+	errTest2 := errors.New("test error 2")
 
 	testCases := map[string]struct {
-		serviceError serviceError
+		err          error
 		errString    string
-		errUnwrapped error
+		errUnwrapped []error
 		panicValue   string
 	}{
-		"no err set panics": {
-			serviceError: serviceError{
-				format:      errorFormatCrash,
-				serviceName: "A",
-			},
-			panicValue: "cannot have nil error in serviceError",
+		"start error without error panics": {
+			err:        &StartError{Service: "A"},
+			panicValue: "cannot have nil error in StartError",
+		},
+		"start error": {
+			err:          &StartError{Service: "A", Err: errTest},
+			errString:    "starting A: test error",
+			errUnwrapped: []error{errTest},
 		},
-		"error set": {
-			serviceError: serviceError{
-				format:      errorFormatCrash,
-				serviceName: "A",
-				err:         errTest,
-			},
+		"crash error without error panics": {
+			err:        &CrashError{Service: "A"},
+			panicValue: "cannot have nil error in CrashError",
+		},
+		"crash error": {
+			err:          &CrashError{Service: "A", Err: errTest},
 			errString:    "A crashed: test error",
-			errUnwrapped: errTest,
+			errUnwrapped: []error{errTest},
+		},
+		"stop error without error panics": {
+			err:        &StopError{Service: "A"},
+			panicValue: "cannot have nil error in StopError",
+		},
+		"stop error": {
+			err:          &StopError{Service: "A", Err: errTest},
+			errString:    "stopping A: test error",
+			errUnwrapped: []error{errTest},
+		},
+		"stop errors": {
+			err: &StopErrors{Errors: []*StopError{
+				{Service: "A", Err: errTest},
+				{Service: "B", Err: errTest2},
+			}},
+			errString:    "stopping A: test error; stopping B: test error 2",
+			errUnwrapped: []error{errTest, errTest2},
+		},
+		"nested stop errors": {
+			err: &StopErrors{Errors: []*StopError{
+				{Service: "group g", Err: &StopErrors{Errors: []*StopError{
+					{Service: "A", Err: errTest},
+					{Service: "B", Err: errTest2},
+				}}},
+				{Service: "sequence s", Err: &StopErrors{Errors: []*StopError{
+					{Service: "C", Err: errTest},
+				}}},
+			}},
+			errString: "stopping group g: (stopping A: test error; stopping B: test error 2); " +
+				"stopping sequence s: stopping C: test error",
+			errUnwrapped: []error{errTest, errTest2},
 		},
 	}
 
@@ -43,17 +78,39 @@ func Test_serviceError(t *testing.T) {
 
 			if testCase.panicValue != "" {
 				assert.PanicsWithValue(t, testCase.panicValue, func() {
-					_ = testCase.serviceError.Error()
+					_ = testCase.err.Error()
 				})
 				return
 			}
 
-			assert.ErrorIs(t, testCase.serviceError, testCase.errUnwrapped)
-			assert.EqualError(t, testCase.serviceError, testCase.errString)
+			for _, errUnwrapped := range testCase.errUnwrapped {
+				assert.ErrorIs(t, testCase.err, errUnwrapped)
+			}
+			assert.EqualError(t, testCase.err, testCase.errString)
 		})
 	}
 }
 
+func Test_StopErrors_errorsAs(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+	var err error = &StopErrors{Errors: []*StopError{
+		{Service: "group g", Err: &StopErrors{Errors: []*StopError{
+			{Service: "A", Err: errTest},
+		}}},
+	}}
+
+	var stopErr *StopError
+	require.ErrorAs(t, err, &stopErr)
+	assert.Equal(t, "group g", stopErr.Service)
+
+	var nestedStopErrors *StopErrors
+	require.ErrorAs(t, stopErr.Err, &nestedStopErrors)
+	require.Len(t, nestedStopErrors.Errors, 1)
+	assert.Equal(t, "A", nestedStopErrors.Errors[0].Service)
+}
+
 func Test_addStopError(t *testing.T) {
 	t.Parallel()
 
@@ -69,7 +126,7 @@ func Test_addStopError(t *testing.T) {
 	}{
 		"all_nils": {},
 		"collected_nil_new_error": {
-			collected:           fmt.Errorf("stopping A: %w", errTest),
+			collected:           addStopError(nil, "A", errTest),
 			newCollectedErrors:  []error{errTest},
 			newCollectedMessage: "stopping A: test error",
 		},
@@ -81,7 +138,7 @@ func Test_addStopError(t *testing.T) {
 		},
 		"collected_new_error": {
 			serviceName:         "B",
-			collected:           fmt.Errorf("stopping A: %w", errTest),
+			collected:           addStopError(nil, "A", errTest),
 			newErr:              errTest2,
 			newCollectedErrors:  []error{errTest, errTest2},
 			newCollectedMessage: "stopping A: test error; stopping B: test error 2",
@@ -14,14 +14,14 @@ type errorsFanIn struct {
 	runErrors          []<-chan error
 	serviceToFaninStop []chan<- struct{}
 	serviceToFaninDone []<-chan struct{}
-	output             chan serviceError
+	output             chan *CrashError
 	runErrorMutex      sync.Mutex
 }
 
 // newErrorsFanIn returns a new errors fan in object
-// together with the output channel for the first service error.
-func newErrorsFanIn() (fanIn *errorsFanIn, reader <-chan serviceError) {
-	output := make(chan serviceError)
+// together with the output channel for the first service crash error.
+func newErrorsFanIn() (fanIn *errorsFanIn, reader <-chan *CrashError) {
+	output := make(chan *CrashError)
 	return &errorsFanIn{
 		output: output,
 	}, output
@@ -86,17 +86,15 @@ func (e *errorsFanIn) fanIn(service string, input <-chan error,
 			return
 		}
 
-		serviceErr := serviceError{
-			format:      errorFormatCrash,
-			serviceName: service,
-			err:         err,
+		e.output <- &CrashError{
+			Service: service,
+			Err:     err,
 		}
-		e.output <- serviceErr
 		close(e.output)
 	}
 }
 
-func isOutputClosed(output <-chan serviceError) (closed bool) {
+func isOutputClosed(output <-chan *CrashError) (closed bool) {
 	select {
 	case _, ok := <-output:
 		return !ok
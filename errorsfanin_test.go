@@ -94,7 +94,7 @@ func Test_errorsFanIn_fanIn(t *testing.T) {
 		t.Parallel()
 
 		e := &errorsFanIn{
-			output: make(chan serviceError),
+			output: make(chan *CrashError),
 		}
 		const serviceName = "test"
 		input := make(chan error)
@@ -115,7 +115,7 @@ func Test_errorsFanIn_fanIn(t *testing.T) {
 		t.Parallel()
 
 		e := &errorsFanIn{
-			output: make(chan serviceError),
+			output: make(chan *CrashError),
 		}
 		input := make(chan error, 1)
 		input <- errors.New("test error")
@@ -160,7 +160,7 @@ func Test_errorsFanIn_fanIn(t *testing.T) {
 		errTest := errors.New("test error")
 
 		e := &errorsFanIn{
-			output: make(chan serviceError, 1),
+			output: make(chan *CrashError, 1),
 		}
 
 		input := make(chan error, 1)
@@ -201,7 +201,7 @@ func Test_errorsFanIn_fanIn(t *testing.T) {
 		t.Parallel()
 
 		e := &errorsFanIn{
-			output: make(chan serviceError, 1),
+			output: make(chan *CrashError, 1),
 		}
 		const service = "test"
 		errTest := errors.New("test error")
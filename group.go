@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 )
 
 var _ Service = (*Group)(nil)
@@ -14,9 +15,14 @@ type Group struct {
 	name            string
 	services        []Service
 	hooks           Hooks
+	drainDelay      time.Duration
+	profilerLabels  bool
+	profiler        profiler
+	crashReporter   *crashReporter
 	startStopMutex  sync.Mutex
 	state           State
 	stateMutex      sync.RWMutex
+	draining        bool
 	fanIn           *errorsFanIn
 	runningServices map[string]struct{}
 	interceptStop   chan struct{}
@@ -35,16 +41,28 @@ func NewGroup(settings GroupSettings) (group *Group, err error) {
 
 	services := make([]Service, len(settings.Services))
 	copy(services, settings.Services)
+	if settings.Watchdog != nil {
+		services = wrapWithWatchdogs(services, *settings.Watchdog)
+	}
 
 	return &Group{
 		name:            settings.Name,
 		services:        services,
 		hooks:           settings.Hooks,
+		drainDelay:      settings.DrainDelay,
+		profilerLabels:  settings.ProfilerLabels || settings.Watchdog != nil,
+		crashReporter:   newCrashReporter(settings.CrashReports),
 		state:           StateStopped,
 		runningServices: make(map[string]struct{}),
 	}, nil
 }
 
+// CrashReports returns the crash reports captured, from the
+// oldest to the most recent one, if crash reports are enabled.
+func (g *Group) CrashReports() (reports []CrashReport) {
+	return g.crashReporter.list()
+}
+
 func (g *Group) String() string {
 	if g.name == "" {
 		return "group"
@@ -84,18 +102,22 @@ func (g *Group) Start(ctx context.Context) (runError <-chan error, startErr erro
 		return nil, fmt.Errorf("%s: %w", g, ErrAlreadyStarted)
 	}
 
+	g.stateMutex.Lock()
 	g.state = StateStarting
+	g.draining = false
+	g.stateMutex.Unlock()
+	g.profiler = newProfiler(ctx, g.profilerLabels, g.String())
 
-	var fanInErrorCh <-chan serviceError
+	var fanInErrorCh <-chan *CrashError
 	g.fanIn, fanInErrorCh = newErrorsFanIn()
 
 	runErrorChannels := make(map[string]<-chan error, len(g.services))
-	startErrorCh := make(chan *serviceError)
+	startErrorCh := make(chan *StartError)
 	runErrorMapMutex := new(sync.Mutex)
 	for _, service := range g.services {
 		serviceString := service.String()
 		go startGroupedServiceAsync(ctx, service, serviceString,
-			g.hooks, startErrorCh, runErrorChannels, runErrorMapMutex)
+			g.hooks, g.profiler, startErrorCh, runErrorChannels, runErrorMapMutex)
 		// assume all the services are going to be running
 		g.runningServices[serviceString] = struct{}{}
 	}
@@ -103,15 +125,15 @@ func (g *Group) Start(ctx context.Context) (runError <-chan error, startErr erro
 	// Collect eventual start error and wait for all services
 	// to be started or failed to start.
 	for range g.services {
-		serviceErr := <-startErrorCh
-		if serviceErr == nil {
+		serviceStartErr := <-startErrorCh
+		if serviceStartErr == nil {
 			continue
 		}
 
-		delete(g.runningServices, serviceErr.serviceName)
+		delete(g.runningServices, serviceStartErr.Service)
 
 		if startErr == nil {
-			startErr = addCtxErrorIfNeeded(serviceErr, ctx.Err())
+			startErr = addCtxErrorIfNeeded(serviceStartErr, ctx.Err())
 		}
 	}
 
@@ -121,6 +143,7 @@ func (g *Group) Start(ctx context.Context) (runError <-chan error, startErr erro
 	}
 
 	for serviceString, runError := range runErrorChannels {
+		g.crashReporter.started(serviceString)
 		g.fanIn.add(serviceString, runError)
 	}
 
@@ -146,17 +169,22 @@ func (g *Group) Start(ctx context.Context) (runError <-chan error, startErr erro
 }
 
 func startGroupedServiceAsync(ctx context.Context, service Starter,
-	serviceString string, hooks Hooks, startErrorCh chan<- *serviceError,
+	serviceString string, hooks Hooks, profiler profiler,
+	startErrorCh chan<- *StartError,
 	runErrorChannels map[string]<-chan error, mutex *sync.Mutex) {
-	hooks.OnStart(serviceString)
-	runError, err := service.Start(ctx)
-	hooks.OnStarted(serviceString, err)
+	var runError <-chan error
+	var err error
+	profiler.do(ctx, profiler.childPath(serviceString), phaseStart,
+		func(ctx context.Context) {
+			hooks.OnStart(serviceString)
+			runError, err = service.Start(ctx)
+			hooks.OnStarted(serviceString, err)
+		})
 
 	if err != nil {
-		startErrorCh <- &serviceError{
-			format:      errorFormatStart,
-			serviceName: serviceString,
-			err:         err,
+		startErrorCh <- &StartError{
+			Service: serviceString,
+			Err:     err,
 		}
 		return
 	}
@@ -173,13 +201,14 @@ func startGroupedServiceAsync(ctx context.Context, service Starter,
 // to the output channel and finally closes this channel.
 // If the stop channel triggers, the function returns.
 func (g *Group) interceptRunError(ready chan<- struct{},
-	input <-chan serviceError, output chan<- error) {
+	input <-chan *CrashError, output chan<- error) {
 	defer close(g.interceptDone)
+	g.profiler.labelGoroutine(phaseRun)
 	close(ready)
 
 	select {
 	case <-g.interceptStop:
-	case serviceErr := <-input:
+	case crashErr := <-input:
 		// Lock the state mutex in case we are stopping
 		// or trying to stop the group at the same time.
 		g.stateMutex.Lock()
@@ -193,28 +222,75 @@ func (g *Group) interceptRunError(ready chan<- struct{},
 		// The first and only service fanned-in run error was
 		// caught and we are not currently stopping the group.
 		g.state = StateCrashed
-		delete(g.runningServices, serviceErr.serviceName)
+		delete(g.runningServices, crashErr.Service)
 		g.stateMutex.Unlock()
 
-		g.hooks.OnCrash(serviceErr.serviceName, serviceErr.err)
+		g.hooks.OnCrash(crashErr.Service, crashErr.Err)
+		g.crashReporter.report(g.hooks, g.profiler.childPath(crashErr.Service),
+			crashErr.Service, crashErr.Err, 0)
 		_ = g.stop()
-		output <- &serviceErr
+		output <- crashErr
 		close(output)
 	}
 }
 
+// Ready returns a nil error if the group is running and all
+// its services implementing `Readier` are ready. Otherwise, it
+// returns an error wrapping `ErrNotReady` describing which
+// services are not ready.
+func (g *Group) Ready() (err error) {
+	g.stateMutex.RLock()
+	state, draining := g.state, g.draining
+	g.stateMutex.RUnlock()
+	if state != StateRunning {
+		return fmt.Errorf("%w: %s is %s", ErrNotReady, g, state)
+	} else if draining {
+		return fmt.Errorf("%w: %s is draining", ErrNotReady, g)
+	}
+	return servicesReady(g.services)
+}
+
+// Drain flips the group readiness off and notifies services
+// implementing `Drainer` of the drain phase, returning once they
+// are all drained or the context is canceled. It is called by `Stop`
+// if a drain delay is set and the group was not drained already, and
+// when the group is drained by a parent service management type.
+func (g *Group) Drain(ctx context.Context) {
+	_ = g.drain(ctx)
+}
+
+func (g *Group) drain(ctx context.Context) (drainers int) {
+	g.stateMutex.Lock()
+	g.draining = true
+	g.stateMutex.Unlock()
+	return drainServices(ctx, g.services)
+}
+
 // Stop stops running services of the group in parallel.
 // If an error occurs for any of the service stop,
 // the other running services will still be stopped.
-// Only the first non nil service stop error encountered
-// is returned, but the hooks can be used to process each
-// error returned.
+// All the service stop errors are returned aggregated in a
+// `*StopErrors` error, and the hooks can also be used to process
+// each error returned.
 // If the group is already stopped, the `ErrAlreadyStopped` error
 // is returned.
 func (g *Group) Stop() (err error) {
 	g.startStopMutex.Lock()
 	defer g.startStopMutex.Unlock()
 
+	if g.drainDelay > 0 {
+		g.stateMutex.RLock()
+		state, draining := g.state, g.draining
+		g.stateMutex.RUnlock()
+		// Skip the drain phase if the group was already drained,
+		// for example by a parent service management type.
+		if state == StateRunning && !draining {
+			// A service crashing during the drain phase is handled
+			// as usual, and the group state becomes crashed.
+			runDrainPhase(g, g.drainDelay)
+		}
+	}
+
 	g.stateMutex.Lock()
 	switch g.state {
 	case StateRunning: // continue stopping the group
@@ -246,7 +322,9 @@ func (g *Group) Stop() (err error) {
 	close(g.interceptStop)
 	<-g.interceptDone
 
+	g.stateMutex.Lock()
 	g.state = StateStopped
+	g.stateMutex.Unlock()
 
 	return err
 }
@@ -254,12 +332,13 @@ func (g *Group) Stop() (err error) {
 // stop stops all running services in the group of services.
 // If a service fails to stop in the group, its error
 // is returned but the other services are still stopped.
-// All service stop errors are wrapped together in the format
-// stopping <name_1>: %w; stopping <name_2>: %w; ...
-// and can be checked individually with errors.Is(err, ErrDefined).
+// All service stop errors are aggregated in a `*StopErrors` error
+// formatted as stopping <name_1>: %w; stopping <name_2>: %w; ...
+// and can be checked individually with errors.Is(err, ErrDefined)
+// or errors.As(err, &stopErr) with `stopErr` of type `*StopError`.
 // Hooks can be used to access each stopping and stop result.
 func (g *Group) stop() (err error) {
-	stopErrors := make(chan serviceError)
+	stopErrors := make(chan StopError)
 	var runningCount uint
 
 	for _, service := range g.services {
@@ -271,22 +350,25 @@ func (g *Group) stop() (err error) {
 		}
 		runningCount++
 
-		go func(service Stopper, serviceString string, stopErrors chan<- serviceError) {
-			g.hooks.OnStop(serviceString)
-			err := service.Stop()
-			g.hooks.OnStopped(serviceString, err)
-			stopErrors <- serviceError{
-				format:      errorFormatStop,
-				serviceName: serviceString,
-				err:         err,
+		go func(service Stopper, serviceString string, stopErrors chan<- StopError) {
+			var err error
+			g.profiler.do(context.Background(), g.profiler.childPath(serviceString), phaseStop,
+				func(context.Context) {
+					g.hooks.OnStop(serviceString)
+					err = service.Stop()
+					g.hooks.OnStopped(serviceString, err)
+				})
+			stopErrors <- StopError{
+				Service: serviceString,
+				Err:     err,
 			}
 		}(service, serviceString, stopErrors)
 	}
 
 	for range runningCount {
 		stopErr := <-stopErrors
-		err = addStopError(err, stopErr.serviceName, stopErr.err)
-		delete(g.runningServices, stopErr.serviceName)
+		err = addStopError(err, stopErr.Service, stopErr.Err)
+		delete(g.runningServices, stopErr.Service)
 	}
 
 	// Only stop the fan in after stopping all services
@@ -2,6 +2,7 @@ package goservices
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/qdm12/goservices/hooks"
 )
@@ -18,6 +19,36 @@ type GroupSettings struct {
 	// since its methods are called in parallel goroutines.
 	// It defaults to a no-op hooks implementation if left unset.
 	Hooks Hooks
+	// ProfilerLabels enables setting pprof goroutine labels
+	// and runtime/trace tasks and regions for the start and stop
+	// of each service, so profiles and execution traces can be
+	// attributed to individual services. See `ProfilerLabelService`
+	// and `ProfilerLabelPhase` for the label keys used.
+	// Note it is automatically enabled if the group is started by
+	// a parent service management type with profiler labels enabled.
+	ProfilerLabels bool
+	// Watchdog, if set, wraps each service with a watchdog
+	// using these settings, reporting service starts and stops
+	// taking too long. Its `Service` field is ignored.
+	// Setting it also enables profiler labels, see `ProfilerLabels`.
+	// It defaults to nil, meaning no watchdog is used.
+	Watchdog *WatchdogSettings
+	// CrashReports, if set, enables capturing a crash report
+	// when a service crashes. Crash reports are retrievable with
+	// the `CrashReports` method, are passed to the hooks if they
+	// implement `HooksCrashReport` and can be written as JSON files
+	// to a crash directory. It defaults to nil, meaning crash
+	// reports are disabled.
+	CrashReports *CrashReportSettings
+	// DrainDelay, if positive, enables a drain phase when `Stop` is
+	// called, before stopping any service. During the drain phase, the
+	// group readiness is flipped off and services implementing `Drainer`
+	// in the tree are notified. The drain phase ends once all drainers
+	// are drained, or once the drain delay elapses. If no service
+	// implements `Drainer`, the full delay is waited, for example for a
+	// load balancer to notice the readiness change.
+	// It defaults to 0, meaning there is no drain phase.
+	DrainDelay time.Duration
 }
 
 // setDefaults sets the defaults for the group settings.
@@ -25,6 +56,18 @@ func (s *GroupSettings) setDefaults() {
 	if s.Hooks == nil {
 		s.Hooks = hooks.NewNoop()
 	}
+
+	if s.Watchdog != nil {
+		watchdog := *s.Watchdog
+		watchdog.setDefaults()
+		s.Watchdog = &watchdog
+	}
+
+	if s.CrashReports != nil {
+		crashReports := *s.CrashReports
+		crashReports.setDefaults()
+		s.CrashReports = &crashReports
+	}
 }
 
 // validate validates the group settings.
@@ -44,5 +87,16 @@ func (s GroupSettings) validate() (err error) {
 		return fmt.Errorf("%w: %s", ErrServicesNotUnique, errMessage)
 	}
 
+	if s.DrainDelay < 0 {
+		return fmt.Errorf("%w: %s", ErrDrainDelayNotValid, s.DrainDelay)
+	}
+
+	if s.Watchdog != nil {
+		err = s.Watchdog.validateWatch()
+		if err != nil {
+			return fmt.Errorf("watchdog: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -320,12 +320,12 @@ func Test_Group_interceptRunError(t *testing.T) {
 		}
 
 		ready := make(chan struct{})
-		input := make(chan serviceError)
+		input := make(chan *CrashError)
 		output := make(chan error)
 		go group.interceptRunError(ready, input, output)
 		<-ready
 
-		input <- serviceError{}
+		input <- &CrashError{}
 
 		<-group.interceptDone
 	})
@@ -364,17 +364,16 @@ func Test_Group_interceptRunError(t *testing.T) {
 		}
 
 		ready := make(chan struct{})
-		input := make(chan serviceError)
+		input := make(chan *CrashError)
 		output := make(chan error)
 
 		go group.interceptRunError(ready, input, output)
 
 		<-ready
 
-		input <- serviceError{
-			format:      errorFormatCrash,
-			serviceName: "A",
-			err:         errTest,
+		input <- &CrashError{
+			Service: "A",
+			Err:     errTest,
 		}
 
 		err := <-output
@@ -0,0 +1,244 @@
+package goservices
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// HealthChecker is an optional interface a service can implement
+// to report its health, to be monitored by a `HealthMonitor`.
+type HealthChecker interface {
+	// Health returns a non-nil error if the service is unhealthy.
+	// It should return promptly if the context is canceled.
+	Health(ctx context.Context) error
+}
+
+// HooksHealth is the interface required to hook into
+// health transitions of a service monitored by a `HealthMonitor`.
+type HooksHealth interface {
+	// OnHealthChanged is called when the service becomes healthy,
+	// with a nil error, or unhealthy, with the last health error.
+	OnHealthChanged(service string, healthy bool, err error)
+}
+
+var _ Service = (*HealthMonitor)(nil)
+
+// HealthMonitor wraps a service and polls its health checker,
+// to detect services which are running but not working, such as
+// a tunnel passing no traffic. When the number of consecutive
+// failed health checks reaches the failure threshold, the underlying
+// service is stopped and a retryable error wrapping `ErrUnhealthy`
+// is sent in the run error channel, so a parent `Restarter` restarts it.
+type HealthMonitor struct {
+	forwarder
+	checker          HealthChecker
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold uint
+	successThreshold uint
+	hooks            HooksHealth
+	startStopMutex   sync.Mutex
+	state            State
+	stateMutex       sync.RWMutex
+	checkCancel      context.CancelFunc
+	interceptStop    chan struct{}
+	interceptDone    chan struct{}
+}
+
+// NewHealthMonitor creates a new health monitor given the settings.
+// It returns an error if any of the settings is not valid.
+func NewHealthMonitor(settings HealthMonitorSettings) (monitor *HealthMonitor, err error) {
+	settings.setDefaults()
+
+	err = settings.validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &HealthMonitor{
+		forwarder:        forwarder{service: settings.Service},
+		checker:          settings.Checker,
+		interval:         settings.Interval,
+		timeout:          settings.Timeout,
+		failureThreshold: settings.FailureThreshold,
+		successThreshold: settings.SuccessThreshold,
+		hooks:            settings.Hooks,
+		state:            StateStopped,
+	}, nil
+}
+
+func (h *HealthMonitor) String() string {
+	return h.service.String()
+}
+
+// Start starts the underlying service and then polls its
+// health checker periodically.
+func (h *HealthMonitor) Start(ctx context.Context) (runError <-chan error, startErr error) {
+	h.startStopMutex.Lock()
+	defer h.startStopMutex.Unlock()
+
+	h.stateMutex.RLock()
+	state := h.state
+	h.stateMutex.RUnlock()
+	if state == StateRunning {
+		return nil, fmt.Errorf("%s: %w", h, ErrAlreadyStarted)
+	}
+
+	h.state = StateStarting
+
+	serviceRunError, startErr := h.service.Start(ctx)
+	if startErr != nil {
+		h.state = StateStopped
+		return nil, startErr
+	}
+
+	// The check context is canceled when stopping, to abort
+	// an eventual health check in progress.
+	checkCtx, checkCancel := context.WithCancel(context.Background())
+	h.checkCancel = checkCancel
+
+	// Hold the state mutex until the intercept goroutine is ready,
+	// in case the service crashes as soon as the goroutine starts.
+	h.stateMutex.Lock()
+	interceptReady := make(chan struct{})
+	runErrorCh := make(chan error)
+	h.interceptStop = make(chan struct{})
+	h.interceptDone = make(chan struct{})
+	go h.interceptRunError(checkCtx, checkCancel, interceptReady, //nolint:contextcheck
+		serviceRunError, runErrorCh)
+	<-interceptReady
+	h.state = StateRunning
+	h.stateMutex.Unlock()
+
+	return runErrorCh, nil
+}
+
+func (h *HealthMonitor) interceptRunError(checkCtx context.Context,
+	checkCancel context.CancelFunc, ready chan<- struct{},
+	input <-chan error, output chan<- error) {
+	defer close(h.interceptDone)
+	// Cancel the check context when crashing, since the monitor
+	// may be started again without being stopped by a `Restarter`.
+	defer checkCancel()
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	close(ready)
+
+	serviceName := h.service.String()
+	var healthy, checking bool
+	var failures, successes uint
+	// Health checks run in their own goroutine, so a slow
+	// check does not delay forwarding a run error.
+	checkResult := make(chan error, 1)
+
+	for {
+		select {
+		case <-h.interceptStop:
+			return
+		case err := <-input:
+			h.stateMutex.Lock()
+			if h.state == StateStopping {
+				// Discard the run error if we are stopping.
+				h.stateMutex.Unlock()
+				return
+			}
+			h.state = StateCrashed
+			h.stateMutex.Unlock()
+			output <- err
+			close(output)
+			return
+		case <-ticker.C:
+			if checking {
+				continue
+			}
+			checking = true
+			go func() {
+				ctx, cancel := context.WithTimeout(checkCtx, h.timeout)
+				defer cancel()
+				checkResult <- h.checker.Health(ctx)
+			}()
+		case healthErr := <-checkResult:
+			checking = false
+			if healthErr == nil {
+				successes++
+				if successes >= h.successThreshold {
+					// Failures are only forgotten once the service
+					// is healthy again for the success threshold.
+					failures = 0
+					if !healthy {
+						healthy = true
+						h.hooks.OnHealthChanged(serviceName, true, nil)
+					}
+				}
+				continue
+			}
+
+			successes = 0
+			failures++
+			if failures < h.failureThreshold {
+				continue
+			}
+
+			h.stateMutex.Lock()
+			if h.state == StateStopping {
+				h.stateMutex.Unlock()
+				return
+			}
+			h.state = StateCrashed
+			h.stateMutex.Unlock()
+
+			h.hooks.OnHealthChanged(serviceName, false, healthErr)
+
+			// Stop the underlying service so it can be started
+			// again, for example by a parent `Restarter`.
+			err := fmt.Errorf("%w: %d health check failures: %w",
+				ErrUnhealthy, failures, healthErr)
+			stopErr := stopReadingRunError(h.service, input)
+			if stopErr != nil {
+				err = fmt.Errorf("%w (and stopping: %w)", err, stopErr)
+			}
+			output <- Retryable(err)
+			close(output)
+			return
+		}
+	}
+}
+
+// Stop stops the health checks and the underlying service.
+// If the monitor is already stopped, the `ErrAlreadyStopped`
+// error is returned.
+func (h *HealthMonitor) Stop() (err error) {
+	h.startStopMutex.Lock()
+	defer h.startStopMutex.Unlock()
+
+	h.stateMutex.Lock()
+	switch h.state {
+	case StateRunning: // continue stopping the monitor
+	case StateCrashed:
+		h.stateMutex.Unlock()
+		<-h.interceptDone
+		h.state = StateStopped
+		return nil
+	case StateStopped:
+		h.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", h, ErrAlreadyStopped)
+	case StateStarting, StateStopping:
+		h.stateMutex.Unlock()
+		panic("bad health monitor implementation code: this code path should be unreachable")
+	}
+	h.state = StateStopping
+	h.stateMutex.Unlock()
+
+	h.checkCancel()
+	err = h.service.Stop()
+
+	// Stop the intercept goroutine after stopping the underlying
+	// service to drain an eventual run error.
+	close(h.interceptStop)
+	<-h.interceptDone
+
+	h.state = StateStopped
+	return err
+}
@@ -0,0 +1,85 @@
+package goservices
+
+import (
+	"fmt"
+	"time"
+)
+
+// HealthMonitorSettings contains settings for a health monitor.
+type HealthMonitorSettings struct {
+	// Service is the service to monitor.
+	// It must be set for settings validation to succeed.
+	Service Service
+	// Checker is the health checker to poll.
+	// It defaults to the service if it implements the
+	// `HealthChecker` interface, and must be set otherwise.
+	Checker HealthChecker
+	// Interval is the period between two health checks.
+	// It defaults to 10 seconds if left unset.
+	Interval time.Duration
+	// Timeout is the maximum duration of a health check.
+	// It defaults to 5 seconds if left unset.
+	Timeout time.Duration
+	// FailureThreshold is the number of failed health checks,
+	// without `SuccessThreshold` consecutive successful checks in
+	// between, after which the service is considered unhealthy and
+	// a crash is injected. It defaults to 3 if left unset.
+	FailureThreshold uint
+	// SuccessThreshold is the number of consecutive successful
+	// health checks after which the service is considered healthy,
+	// and after which previous failed checks are forgotten.
+	// It defaults to 1 if left unset.
+	SuccessThreshold uint
+	// Hooks are the hooks called on health transitions.
+	// It defaults to no-op hooks if left unset.
+	Hooks HooksHealth
+}
+
+// setDefaults sets the defaults for the health monitor settings.
+func (h *HealthMonitorSettings) setDefaults() {
+	if h.Checker == nil {
+		h.Checker, _ = h.Service.(HealthChecker)
+	}
+
+	if h.Interval == 0 {
+		const defaultInterval = 10 * time.Second
+		h.Interval = defaultInterval
+	}
+
+	if h.Timeout == 0 {
+		const defaultTimeout = 5 * time.Second
+		h.Timeout = defaultTimeout
+	}
+
+	if h.FailureThreshold == 0 {
+		const defaultFailureThreshold = 3
+		h.FailureThreshold = defaultFailureThreshold
+	}
+
+	if h.SuccessThreshold == 0 {
+		h.SuccessThreshold = 1
+	}
+
+	if h.Hooks == nil {
+		h.Hooks = noopHooksHealth{}
+	}
+}
+
+// validate validates the health monitor settings.
+func (h HealthMonitorSettings) validate() (err error) {
+	switch {
+	case h.Service == nil:
+		return fmt.Errorf("%w", ErrNoService)
+	case h.Checker == nil:
+		return fmt.Errorf("%w", ErrHealthCheckerIsNil)
+	case h.Interval < 0:
+		return fmt.Errorf("%w: interval %s", ErrHealthDurationNotValid, h.Interval)
+	case h.Timeout < 0:
+		return fmt.Errorf("%w: timeout %s", ErrHealthDurationNotValid, h.Timeout)
+	}
+	return nil
+}
+
+type noopHooksHealth struct{}
+
+func (noopHooksHealth) OnHealthChanged(string, bool, error) {}
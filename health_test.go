@@ -0,0 +1,245 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testHealthChecker struct {
+	err atomic.Pointer[error]
+}
+
+func (c *testHealthChecker) Health(context.Context) error {
+	err := c.err.Load()
+	if err == nil {
+		return nil
+	}
+	return *err
+}
+
+type testHooksHealth struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (h *testHooksHealth) OnHealthChanged(service string, healthy bool, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.events = append(h.events, fmt.Sprintf("%s healthy=%t err=%v", service, healthy, err))
+}
+
+func (h *testHooksHealth) getEvents() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+func Test_NewHealthMonitor(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHealthMonitor(HealthMonitorSettings{})
+	assert.ErrorIs(t, err, ErrNoService)
+
+	_, err = NewHealthMonitor(HealthMonitorSettings{
+		Service: NewRunWrapper("A", nil),
+	})
+	assert.EqualError(t, err, "validating settings: health checker is nil")
+
+	_, err = NewHealthMonitor(HealthMonitorSettings{
+		Service: NewRunWrapper("A", nil),
+		Checker: &testHealthChecker{},
+		Timeout: -time.Second,
+	})
+	assert.EqualError(t, err, "validating settings: health duration is not valid: timeout -1s")
+}
+
+func Test_HealthMonitor(t *testing.T) {
+	t.Parallel()
+
+	var starts atomic.Int32
+	service := NewRunWrapper("A", func(ctx context.Context,
+		ready chan<- struct{}, _, stopError chan<- error) {
+		starts.Add(1)
+		close(ready)
+		<-ctx.Done()
+		close(stopError)
+	})
+
+	checker := &testHealthChecker{}
+	hooks := &testHooksHealth{}
+	monitor, err := NewHealthMonitor(HealthMonitorSettings{
+		Service:          service,
+		Checker:          checker,
+		Interval:         time.Millisecond,
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		Hooks:            hooks,
+	})
+	require.NoError(t, err)
+
+	restarter, err := NewRestarter(RestarterSettings{
+		Service:              monitor,
+		RestartOnlyRetryable: true,
+	})
+	require.NoError(t, err)
+
+	runError, err := restarter.Start(context.Background())
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return len(hooks.getEvents()) == 1
+	}, time.Second, time.Millisecond)
+
+	errTest := errors.New("no traffic")
+	checker.err.Store(&errTest)
+
+	require.Eventually(t, func() bool {
+		return starts.Load() >= 2
+	}, time.Second, time.Millisecond)
+
+	checker.err.Store(nil)
+	require.Eventually(t, func() bool {
+		events := hooks.getEvents()
+		return len(events) >= 3 && events[len(events)-1] == "A healthy=true err=<nil>"
+	}, time.Second, time.Millisecond)
+
+	err = restarter.Stop()
+	require.NoError(t, err)
+	select {
+	case err = <-runError:
+		t.Fatalf("unexpected run error: %s", err)
+	default:
+	}
+
+	// The service may be restarted more than once
+	// before the health checker becomes healthy again.
+	expectedFirstEvents := []string{
+		"A healthy=true err=<nil>",
+		"A healthy=false err=no traffic",
+	}
+	assert.Equal(t, expectedFirstEvents, hooks.getEvents()[:2])
+}
+
+func Test_HealthMonitor_crashWhilstStopping(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	serviceRunError := make(chan error)
+	service := NewMockService(ctrl)
+	service.EXPECT().String().Return("A").AnyTimes()
+	service.EXPECT().Start(gomock.Any()).Return(serviceRunError, nil)
+	service.EXPECT().Stop().DoAndReturn(func() error {
+		// The service crashes whilst being stopped, and blocks
+		// until its run error is read.
+		serviceRunError <- errors.New("crashed")
+		return nil
+	})
+
+	checker := &testHealthChecker{}
+	errUnhealthy := errors.New("unhealthy")
+	checker.err.Store(&errUnhealthy)
+	monitor, err := NewHealthMonitor(HealthMonitorSettings{
+		Service:          service,
+		Checker:          checker,
+		Interval:         time.Millisecond,
+		FailureThreshold: 1,
+	})
+	require.NoError(t, err)
+
+	runError, err := monitor.Start(context.Background())
+	require.NoError(t, err)
+
+	err = <-runError
+	assert.ErrorIs(t, err, ErrUnhealthy)
+
+	err = monitor.Stop()
+	require.NoError(t, err)
+}
+
+type testHealthCheckerFunc func(ctx context.Context) error
+
+func (f testHealthCheckerFunc) Health(ctx context.Context) error { return f(ctx) }
+
+func Test_HealthMonitor_slowCheckCrash(t *testing.T) {
+	t.Parallel()
+
+	crash := make(chan error)
+	checkStarted := make(chan struct{})
+	var checkStartedOnce sync.Once
+	monitor, err := NewHealthMonitor(HealthMonitorSettings{
+		Service: newTestService("A", crash, nil),
+		Checker: testHealthCheckerFunc(func(ctx context.Context) error {
+			checkStartedOnce.Do(func() { close(checkStarted) })
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		Interval: time.Millisecond,
+		Timeout:  time.Hour,
+	})
+	require.NoError(t, err)
+
+	runError, err := monitor.Start(context.Background())
+	require.NoError(t, err)
+
+	<-checkStarted
+	errTest := errors.New("test crash")
+	crash <- errTest
+	select {
+	case err = <-runError:
+		assert.ErrorIs(t, err, errTest)
+	case <-time.After(time.Second):
+		t.Fatal("run error delayed by the health check in progress")
+	}
+
+	err = monitor.Stop()
+	require.NoError(t, err)
+}
+
+func Test_HealthMonitor_successThresholdAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	errCheck := errors.New("check failed")
+	results := make(chan error)
+	hooks := &testHooksHealth{}
+	monitor, err := NewHealthMonitor(HealthMonitorSettings{
+		Service: newTestService("A", nil, nil),
+		Checker: testHealthCheckerFunc(func(context.Context) error {
+			return <-results
+		}),
+		Interval:         time.Millisecond,
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		Hooks:            hooks,
+	})
+	require.NoError(t, err)
+
+	runError, err := monitor.Start(context.Background())
+	require.NoError(t, err)
+
+	// A single success between two failures is below the success
+	// threshold, so the second failure reaches the failure threshold.
+	for _, result := range []error{nil, nil, errCheck, nil, errCheck} {
+		results <- result
+	}
+
+	err = <-runError
+	assert.ErrorIs(t, err, ErrUnhealthy)
+	assert.EqualError(t, err, "service is unhealthy: 2 health check failures: check failed")
+	expectedEvents := []string{
+		"A healthy=true err=<nil>",
+		"A healthy=false err=check failed",
+	}
+	assert.Equal(t, expectedEvents, hooks.getEvents())
+
+	err = monitor.Stop()
+	require.NoError(t, err)
+}
@@ -0,0 +1,221 @@
+package goservices
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type heartbeatContextKey struct{}
+
+// HeartbeatFromContext returns the heartbeat function set in the
+// context given by a `HeartbeatWatchdog` starting the service.
+// A service should call it periodically from its main loop to signal
+// it is alive. If no heartbeat function is set in the context, a
+// no-op function is returned, so it is always safe to call it.
+// Note the `RunWrapper` sets the heartbeat function of its start
+// context in the context passed to its run function.
+func HeartbeatFromContext(ctx context.Context) (heartbeat func()) {
+	heartbeat, ok := ctx.Value(heartbeatContextKey{}).(func())
+	if !ok {
+		return func() {}
+	}
+	return heartbeat
+}
+
+func contextWithHeartbeat(ctx context.Context, heartbeat func()) context.Context {
+	return context.WithValue(ctx, heartbeatContextKey{}, heartbeat)
+}
+
+var _ Service = (*HeartbeatWatchdog)(nil)
+
+// HeartbeatWatchdog wraps a service and treats a missed heartbeat
+// deadline as a crash, for services which can deadlock internally
+// without ever sending an error in their run error channel.
+// When no heartbeat is received within the timeout, the underlying
+// service is stopped and an error wrapping `ErrHeartbeatMissed` is
+// sent in the run error channel, so a parent `Restarter` or service
+// management type reacts as for any other crash.
+type HeartbeatWatchdog struct {
+	forwarder
+	timeout        time.Duration
+	startStopMutex sync.Mutex
+	state          State
+	stateMutex     sync.RWMutex
+	interceptStop  chan struct{}
+	interceptDone  chan struct{}
+}
+
+// NewHeartbeatWatchdog creates a new heartbeat watchdog given the settings.
+// It returns an error if any of the settings is not valid.
+func NewHeartbeatWatchdog(settings HeartbeatWatchdogSettings) (
+	watchdog *HeartbeatWatchdog, err error) {
+	settings.setDefaults()
+
+	err = settings.validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &HeartbeatWatchdog{
+		forwarder: forwarder{service: settings.Service},
+		timeout:   settings.Timeout,
+		state:     StateStopped,
+	}, nil
+}
+
+func (h *HeartbeatWatchdog) String() string {
+	return h.service.String()
+}
+
+// Start starts the underlying service with a heartbeat function set
+// in its start context. The heartbeat deadline starts once the
+// underlying service started successfully.
+func (h *HeartbeatWatchdog) Start(ctx context.Context) (runError <-chan error, startErr error) {
+	h.startStopMutex.Lock()
+	defer h.startStopMutex.Unlock()
+
+	h.stateMutex.RLock()
+	state := h.state
+	h.stateMutex.RUnlock()
+	if state == StateRunning {
+		return nil, fmt.Errorf("%s: %w", h, ErrAlreadyStarted)
+	}
+
+	h.state = StateStarting
+
+	beats := make(chan struct{}, 1)
+	heartbeat := func() {
+		select {
+		case beats <- struct{}{}:
+		default: // a heartbeat is already pending
+		}
+	}
+	ctx = contextWithHeartbeat(ctx, heartbeat)
+
+	serviceRunError, startErr := h.service.Start(ctx)
+	if startErr != nil {
+		h.state = StateStopped
+		return nil, startErr
+	}
+
+	// Hold the state mutex until the intercept goroutine is ready,
+	// in case the service crashes as soon as the goroutine starts.
+	h.stateMutex.Lock()
+	interceptReady := make(chan struct{})
+	runErrorCh := make(chan error)
+	h.interceptStop = make(chan struct{})
+	h.interceptDone = make(chan struct{})
+	go h.interceptRunError(interceptReady, beats, serviceRunError, runErrorCh)
+	<-interceptReady
+	h.state = StateRunning
+	h.stateMutex.Unlock()
+
+	return runErrorCh, nil
+}
+
+func (h *HeartbeatWatchdog) interceptRunError(ready chan<- struct{},
+	beats <-chan struct{}, input <-chan error, output chan<- error) {
+	defer close(h.interceptDone)
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+	close(ready)
+
+	for {
+		select {
+		case <-h.interceptStop:
+			return
+		case <-beats:
+			timer.Reset(h.timeout)
+		case err := <-input:
+			h.stateMutex.Lock()
+			if h.state == StateStopping {
+				// Discard the run error if we are stopping.
+				h.stateMutex.Unlock()
+				return
+			}
+			h.state = StateCrashed
+			h.stateMutex.Unlock()
+			output <- err
+			close(output)
+			return
+		case <-timer.C:
+			h.stateMutex.Lock()
+			if h.state == StateStopping {
+				h.stateMutex.Unlock()
+				return
+			}
+			h.state = StateCrashed
+			h.stateMutex.Unlock()
+
+			// Stop the underlying service so it can be started
+			// again, for example by a parent `Restarter`. The stop
+			// is limited to the heartbeat timeout, since a service
+			// missing its heartbeat is likely deadlocked.
+			err := fmt.Errorf("%w: no heartbeat for %s", ErrHeartbeatMissed, h.timeout)
+			stopErr := h.stopWithTimeout(input)
+			if stopErr != nil {
+				err = fmt.Errorf("%w (and stopping: %w)", err, stopErr)
+			}
+			output <- err
+			close(output)
+			return
+		}
+	}
+}
+
+// stopWithTimeout stops the underlying service for at most the heartbeat
+// timeout, after which it returns an error wrapping `ErrStopTimeout` and
+// leaves the service stopping in the background.
+func (h *HeartbeatWatchdog) stopWithTimeout(runError <-chan error) (err error) {
+	stopResult := make(chan error, 1)
+	go func() {
+		stopResult <- stopReadingRunError(h.service, runError)
+	}()
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+	select {
+	case err = <-stopResult:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%w after %s", ErrStopTimeout, h.timeout)
+	}
+}
+
+// Stop stops the underlying service.
+// If the watchdog is already stopped, the `ErrAlreadyStopped`
+// error is returned.
+func (h *HeartbeatWatchdog) Stop() (err error) {
+	h.startStopMutex.Lock()
+	defer h.startStopMutex.Unlock()
+
+	h.stateMutex.Lock()
+	switch h.state {
+	case StateRunning: // continue stopping the watchdog
+	case StateCrashed:
+		h.stateMutex.Unlock()
+		<-h.interceptDone
+		h.state = StateStopped
+		return nil
+	case StateStopped:
+		h.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", h, ErrAlreadyStopped)
+	case StateStarting, StateStopping:
+		h.stateMutex.Unlock()
+		panic("bad heartbeat watchdog implementation code: this code path should be unreachable")
+	}
+	h.state = StateStopping
+	h.stateMutex.Unlock()
+
+	err = h.service.Stop()
+
+	// Stop the intercept goroutine after stopping the underlying
+	// service to drain an eventual run error.
+	close(h.interceptStop)
+	<-h.interceptDone
+
+	h.state = StateStopped
+	return err
+}
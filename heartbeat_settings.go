@@ -0,0 +1,37 @@
+package goservices
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeartbeatWatchdogSettings contains settings for a heartbeat watchdog.
+type HeartbeatWatchdogSettings struct {
+	// Service is the service to watch, which must call its
+	// heartbeat function obtained with `HeartbeatFromContext`.
+	// It must be set for settings validation to succeed.
+	Service Service
+	// Timeout is the maximum duration allowed between two
+	// heartbeats of the service once it is started.
+	// It defaults to 30 seconds if left unset.
+	Timeout time.Duration
+}
+
+// setDefaults sets the defaults for the heartbeat watchdog settings.
+func (h *HeartbeatWatchdogSettings) setDefaults() {
+	if h.Timeout == 0 {
+		const defaultTimeout = 30 * time.Second
+		h.Timeout = defaultTimeout
+	}
+}
+
+// validate validates the heartbeat watchdog settings.
+func (h HeartbeatWatchdogSettings) validate() (err error) {
+	switch {
+	case h.Service == nil:
+		return fmt.Errorf("%w", ErrNoService)
+	case h.Timeout < 0:
+		return fmt.Errorf("%w: %s", ErrHeartbeatTimeoutNotValid, h.Timeout)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HeartbeatFromContext(t *testing.T) {
+	t.Parallel()
+
+	heartbeat := HeartbeatFromContext(context.Background())
+	assert.NotPanics(t, heartbeat)
+
+	called := false
+	ctx := contextWithHeartbeat(context.Background(), func() { called = true })
+	HeartbeatFromContext(ctx)()
+	assert.True(t, called)
+}
+
+func Test_NewHeartbeatWatchdog(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHeartbeatWatchdog(HeartbeatWatchdogSettings{})
+	assert.ErrorIs(t, err, ErrNoService)
+
+	_, err = NewHeartbeatWatchdog(HeartbeatWatchdogSettings{
+		Service: NewRunWrapper("A", nil),
+		Timeout: -time.Second,
+	})
+	assert.EqualError(t, err, "validating settings: heartbeat timeout is not valid: -1s")
+}
+
+// newHeartbeatService returns a service beating every millisecond,
+// until its `hang` channel is closed, simulating a deadlock.
+func newHeartbeatService(starts *atomic.Int32, hang <-chan struct{}) *RunWrapper {
+	return newTestService("A", nil, func(ctx context.Context) {
+		starts.Add(1)
+		heartbeat := HeartbeatFromContext(ctx)
+		go func() {
+			ticker := time.NewTicker(time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					select {
+					case <-hang:
+					default:
+						heartbeat()
+					}
+				}
+			}
+		}()
+	})
+}
+
+func Test_HeartbeatWatchdog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("healthy_service_stopped", func(t *testing.T) {
+		t.Parallel()
+
+		starts := new(atomic.Int32)
+		watchdog, err := NewHeartbeatWatchdog(HeartbeatWatchdogSettings{
+			Service: newHeartbeatService(starts, nil),
+			Timeout: 20 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		runError, err := watchdog.Start(context.Background())
+		require.NoError(t, err)
+
+		select {
+		case err = <-runError:
+			t.Fatalf("unexpected run error: %s", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		err = watchdog.Stop()
+		require.NoError(t, err)
+		err = watchdog.Stop()
+		assert.ErrorIs(t, err, ErrAlreadyStopped)
+	})
+
+	t.Run("missed_heartbeat_restarted", func(t *testing.T) {
+		t.Parallel()
+
+		starts := new(atomic.Int32)
+		hang := make(chan struct{})
+		watchdog, err := NewHeartbeatWatchdog(HeartbeatWatchdogSettings{
+			Service: newHeartbeatService(starts, hang),
+			Timeout: 20 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		hooks := new(testCrashHooks)
+		restarter, err := NewRestarter(RestarterSettings{
+			Service: watchdog,
+			Hooks:   hooks,
+		})
+		require.NoError(t, err)
+
+		runError, err := restarter.Start(context.Background())
+		require.NoError(t, err)
+
+		close(hang)
+		require.Eventually(t, func() bool {
+			return starts.Load() >= 2
+		}, time.Second, time.Millisecond)
+
+		err = hooks.firstCrash()
+		assert.ErrorIs(t, err, ErrHeartbeatMissed)
+		assert.EqualError(t, err, "heartbeat missed: no heartbeat for 20ms")
+
+		err = restarter.Stop()
+		require.NoError(t, err)
+		select {
+		case err = <-runError:
+			t.Fatalf("unexpected run error: %s", err)
+		default:
+		}
+	})
+}
+
+func Test_HeartbeatWatchdog_crashWhilstStopping(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	serviceRunError := make(chan error)
+	service := NewMockService(ctrl)
+	service.EXPECT().String().Return("A").AnyTimes()
+	service.EXPECT().Start(gomock.Any()).Return(serviceRunError, nil)
+	service.EXPECT().Stop().DoAndReturn(func() error {
+		// The service crashes whilst being stopped, and blocks
+		// until its run error is read.
+		serviceRunError <- errors.New("crashed")
+		return nil
+	})
+
+	watchdog, err := NewHeartbeatWatchdog(HeartbeatWatchdogSettings{
+		Service: service,
+		Timeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	runError, err := watchdog.Start(context.Background())
+	require.NoError(t, err)
+
+	err = <-runError
+	assert.EqualError(t, err, "heartbeat missed: no heartbeat for 10ms")
+
+	err = watchdog.Stop()
+	require.NoError(t, err)
+}
+
+func Test_HeartbeatWatchdog_stopDeadlocked(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	unblockStop := make(chan struct{})
+	t.Cleanup(func() { close(unblockStop) })
+	service := NewMockService(ctrl)
+	service.EXPECT().String().Return("A").AnyTimes()
+	service.EXPECT().Start(gomock.Any()).Return(make(chan error), nil)
+	service.EXPECT().Stop().DoAndReturn(func() error {
+		<-unblockStop
+		return nil
+	})
+
+	watchdog, err := NewHeartbeatWatchdog(HeartbeatWatchdogSettings{
+		Service: service,
+		Timeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	runError, err := watchdog.Start(context.Background())
+	require.NoError(t, err)
+
+	err = <-runError
+	assert.ErrorIs(t, err, ErrHeartbeatMissed)
+	assert.ErrorIs(t, err, ErrStopTimeout)
+	assert.EqualError(t, err, "heartbeat missed: no heartbeat for 10ms "+
+		"(and stopping: stop timeout exceeded after 10ms)")
+
+	err = watchdog.Stop()
+	require.NoError(t, err)
+}
+
+type testCrashHooks struct {
+	crashes atomic.Pointer[error]
+}
+
+func (h *testCrashHooks) OnStart(string)              {}
+func (h *testCrashHooks) OnStarted(string, error)     {}
+func (h *testCrashHooks) OnStop(string)               {}
+func (h *testCrashHooks) OnStopped(string, error)     {}
+func (h *testCrashHooks) OnCrash(_ string, err error) { h.crashes.CompareAndSwap(nil, &err) }
+
+func (h *testCrashHooks) firstCrash() error { return *h.crashes.Load() }
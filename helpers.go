@@ -28,3 +28,24 @@ func joinStrings(strings []string, lastJoin string) (result string) {
 
 	return result
 }
+
+// stopReadingRunError stops the service whilst reading its run error
+// channel, since the service may crash whilst being stopped and block
+// sending its run error until it is read. Such run error is discarded.
+func stopReadingRunError(service Stopper, runError <-chan error) (err error) {
+	stopResult := make(chan error)
+	go func() {
+		stopResult <- service.Stop()
+	}()
+
+	for {
+		select {
+		case err = <-stopResult:
+			return err
+		case _, ok := <-runError:
+			if !ok {
+				runError = nil
+			}
+		}
+	}
+}
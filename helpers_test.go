@@ -1,6 +1,9 @@
 package goservices
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -8,17 +11,98 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// newTestService returns a service running until it is stopped,
+// or until it crashes with an error received from `crash` if it is
+// not nil. The `onStart` function, if not nil, is called with the
+// run context when the service starts.
+func newTestService(name string, crash <-chan error,
+	onStart func(ctx context.Context)) *RunWrapper {
+	return NewRunWrapper(name, func(ctx context.Context,
+		ready chan<- struct{}, runError, stopError chan<- error) {
+		if onStart != nil {
+			onStart(ctx)
+		}
+		close(ready)
+		select {
+		case <-ctx.Done():
+			close(stopError)
+		case err := <-crash:
+			runError <- err
+			close(runError)
+		}
+	})
+}
+
+// testOptionalService is a test service implementing the optional
+// `Readier` and `Drainer` interfaces. It is ready unless a not ready
+// error is set, calls `onDrain` if not nil when drained, and records
+// its drain and stop events.
+type testOptionalService struct {
+	*RunWrapper
+	notReadyErr atomic.Pointer[error]
+	onDrain     func()
+	mutex       sync.Mutex
+	events      []string
+}
+
+func newTestOptionalService(name string) *testOptionalService {
+	return &testOptionalService{
+		RunWrapper: newTestService(name, nil, nil),
+	}
+}
+
+func (s *testOptionalService) setNotReady(err error) {
+	if err == nil {
+		s.notReadyErr.Store(nil)
+		return
+	}
+	s.notReadyErr.Store(&err)
+}
+
+func (s *testOptionalService) Ready() error {
+	err := s.notReadyErr.Load()
+	if err == nil {
+		return nil
+	}
+	return *err
+}
+
+func (s *testOptionalService) Drain(context.Context) {
+	s.record("draining")
+	if s.onDrain != nil {
+		s.onDrain()
+	}
+	s.record("drained")
+}
+
+func (s *testOptionalService) Stop() (err error) {
+	err = s.RunWrapper.Stop()
+	s.record("stopped")
+	return err
+}
+
+func (s *testOptionalService) record(event string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *testOptionalService) getEvents() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.events...)
+}
+
 func checkErrIsErrTest(t *testing.T, err error, serviceName string,
 	sentinelErr error) {
 	t.Helper()
 	assert.ErrorIs(t, err, sentinelErr)
 	assert.EqualError(t, err, serviceName+" crashed: "+sentinelErr.Error())
-	expectedServiceErr := serviceError{
-		format:      errorFormatCrash,
-		serviceName: serviceName,
-		err:         sentinelErr,
+	expectedCrashErr := &CrashError{
+		Service: serviceName,
+		Err:     sentinelErr,
 	}
-	assert.Equal(t, expectedServiceErr, err)
+	assert.Equal(t, expectedCrashErr, err)
 }
 
 type syncMutexTest interface {
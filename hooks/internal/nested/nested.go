@@ -0,0 +1,117 @@
+// Package nested provides the service handler hooks and root
+// service wrapper shared by hooks recording start and stop phases
+// nested under the phase of a parent composite service.
+package nested
+
+import (
+	"context"
+
+	"github.com/qdm12/goservices"
+)
+
+// Phases recorded for services.
+const (
+	PhaseStart = "start"
+	PhaseStop  = "stop"
+	PhaseCrash = "crash"
+)
+
+// Key identifies the phase of a service being recorded.
+type Key struct {
+	Phase   string
+	Service string
+}
+
+// Parent returns the open item of the parent service for the
+// phase given, if the parent is not empty and its item is open.
+// A crash is nested under the start phase of its parent.
+func Parent[T any](open map[Key]T, phase, parent string) (item T, ok bool) {
+	if parent == "" {
+		return item, false
+	}
+	if phase == PhaseCrash {
+		phase = PhaseStart
+	}
+	item, ok = open[Key{Phase: phase, Service: parent}]
+	return item, ok
+}
+
+// Recorder records phases, and is called by the hooks with an
+// empty parent for phases without parent.
+type Recorder interface {
+	Begin(phase, parent, service string)
+	End(phase, service string, err error)
+	Crash(parent, service string, err error)
+}
+
+var _ goservices.Hooks = (*Hooks)(nil)
+
+// Hooks implements service handler hooks recording phases
+// nested under the phase of a parent service.
+type Hooks struct {
+	recorder Recorder
+	parent   string
+}
+
+// NewHooks returns hooks recording phases as children of the phase
+// of the parent service, or without parent if `parent` is empty.
+func NewHooks(recorder Recorder, parent string) *Hooks {
+	return &Hooks{
+		recorder: recorder,
+		parent:   parent,
+	}
+}
+
+// OnStart begins the start phase of the service.
+func (h *Hooks) OnStart(service string) { h.recorder.Begin(PhaseStart, h.parent, service) }
+
+// OnStarted ends the start phase of the service.
+func (h *Hooks) OnStarted(service string, err error) { h.recorder.End(PhaseStart, service, err) }
+
+// OnStop begins the stop phase of the service.
+func (h *Hooks) OnStop(service string) { h.recorder.Begin(PhaseStop, h.parent, service) }
+
+// OnStopped ends the stop phase of the service.
+func (h *Hooks) OnStopped(service string, err error) { h.recorder.End(PhaseStop, service, err) }
+
+// OnCrash records an instant crash of the service.
+func (h *Hooks) OnCrash(service string, err error) { h.recorder.Crash(h.parent, service, err) }
+
+var _ goservices.Service = (*Service)(nil)
+
+// Service wraps a service to record its own start and stop phases.
+type Service struct {
+	service goservices.Service
+	hooks   goservices.Hooks
+}
+
+// Wrap returns a service wrapping the given service, calling
+// the hooks given for its own start and stop calls.
+func Wrap(service goservices.Service, hooks goservices.Hooks) *Service {
+	return &Service{
+		service: service,
+		hooks:   hooks,
+	}
+}
+
+func (s *Service) String() string {
+	return s.service.String()
+}
+
+// Start starts the underlying service, recording its start phase.
+func (s *Service) Start(ctx context.Context) (runError <-chan error, startErr error) {
+	serviceString := s.service.String()
+	s.hooks.OnStart(serviceString)
+	runError, startErr = s.service.Start(ctx)
+	s.hooks.OnStarted(serviceString, startErr)
+	return runError, startErr
+}
+
+// Stop stops the underlying service, recording its stop phase.
+func (s *Service) Stop() (err error) {
+	serviceString := s.service.String()
+	s.hooks.OnStop(serviceString)
+	err = s.service.Stop()
+	s.hooks.OnStopped(serviceString, err)
+	return err
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qdm12/goservices"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// ServeHTTP writes all the metrics recorded in the Prometheus
+// text exposition format to the response writer.
+// The Metrics instance can therefore be used directly as
+// handler for an `httpserver.Server`.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	buffer := new(bytes.Buffer)
+	m.write(buffer)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buffer.Bytes())
+}
+
+func (m *Metrics) write(buffer *bytes.Buffer) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	services := make([]string, 0, len(m.services))
+	for service := range m.services {
+		services = append(services, service)
+	}
+	slices.Sort(services)
+
+	m.writeHistograms(buffer, "service_start_duration_seconds",
+		"Duration in seconds taken by services to start.", services,
+		func(metrics *serviceMetrics) histogram { return metrics.startDurations })
+	m.writeHistograms(buffer, "service_stop_duration_seconds",
+		"Duration in seconds taken by services to stop.", services,
+		func(metrics *serviceMetrics) histogram { return metrics.stopDurations })
+	m.writeCounters(buffer, "service_start_failures_total",
+		"Total number of service start failures.", services,
+		func(metrics *serviceMetrics) uint64 { return metrics.startFailures })
+	m.writeCounters(buffer, "service_stop_failures_total",
+		"Total number of service stop failures.", services,
+		func(metrics *serviceMetrics) uint64 { return metrics.stopFailures })
+	m.writeCounters(buffer, "service_crashes_total",
+		"Total number of service crashes.", services,
+		func(metrics *serviceMetrics) uint64 { return metrics.crashes })
+	m.writeCounters(buffer, "service_restarts_total",
+		"Total number of service restarts after a crash.", services,
+		func(metrics *serviceMetrics) uint64 { return metrics.restarts })
+	m.writeStates(buffer, services)
+}
+
+func (m *Metrics) name(name string) string {
+	if m.namespace == "" {
+		return name
+	}
+	return m.namespace + "_" + name
+}
+
+func writeHeader(buffer *bytes.Buffer, name, help, metricType string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buffer, "# TYPE %s %s\n", name, metricType)
+}
+
+func (m *Metrics) writeCounters(buffer *bytes.Buffer, name, help string,
+	services []string, getValue func(metrics *serviceMetrics) uint64) {
+	name = m.name(name)
+	writeHeader(buffer, name, help, "counter")
+	for _, service := range services {
+		value := getValue(m.services[service])
+		fmt.Fprintf(buffer, "%s{service=\"%s\"} %d\n",
+			name, escapeLabelValue(service), value)
+	}
+}
+
+func (m *Metrics) writeHistograms(buffer *bytes.Buffer, name, help string,
+	services []string, getHistogram func(metrics *serviceMetrics) histogram) {
+	name = m.name(name)
+	writeHeader(buffer, name, help, "histogram")
+	for _, service := range services {
+		histogram := getHistogram(m.services[service])
+		service = escapeLabelValue(service)
+		var cumulativeCount uint64
+		for i, upperBound := range histogram.upperBounds {
+			cumulativeCount += histogram.counts[i]
+			fmt.Fprintf(buffer, "%s_bucket{service=\"%s\",le=\"%s\"} %d\n",
+				name, service, formatFloat(upperBound), cumulativeCount)
+		}
+		fmt.Fprintf(buffer, "%s_bucket{service=\"%s\",le=\"+Inf\"} %d\n",
+			name, service, histogram.count)
+		fmt.Fprintf(buffer, "%s_sum{service=\"%s\"} %s\n",
+			name, service, formatFloat(histogram.sum))
+		fmt.Fprintf(buffer, "%s_count{service=\"%s\"} %d\n",
+			name, service, histogram.count)
+	}
+}
+
+// writeStates writes the state gauge for each service, with one
+// time series per possible state where the current state has the
+// value 1 and other states have the value 0.
+func (m *Metrics) writeStates(buffer *bytes.Buffer, services []string) {
+	name := m.name("service_state")
+	writeHeader(buffer, name,
+		"Current state of services, set to 1 for the current state.", "gauge")
+	states := []goservices.State{
+		goservices.StateStopped,
+		goservices.StateStarting,
+		goservices.StateRunning,
+		goservices.StateStopping,
+		goservices.StateCrashed,
+	}
+	for _, service := range services {
+		currentState := m.services[service].state
+		escapedService := escapeLabelValue(service)
+		for _, state := range states {
+			value := 0
+			if state == currentState {
+				value = 1
+			}
+			fmt.Fprintf(buffer, "%s{service=\"%s\",state=\"%s\"} %d\n",
+				name, escapedService, state, value)
+		}
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+var labelValueReplacer = strings.NewReplacer( //nolint:gochecknoglobals
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
@@ -0,0 +1,29 @@
+package metrics
+
+// histogram is a cumulative histogram with fixed buckets.
+type histogram struct {
+	upperBounds []float64
+	// counts contains the non-cumulative count for each
+	// bucket upper bound.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(upperBounds []float64) histogram {
+	return histogram{
+		upperBounds: upperBounds,
+		counts:      make([]uint64, len(upperBounds)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	h.sum += value
+	h.count++
+	for i, upperBound := range h.upperBounds {
+		if value <= upperBound {
+			h.counts[i]++
+			return
+		}
+	}
+}
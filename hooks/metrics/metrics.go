@@ -0,0 +1,149 @@
+// Package metrics provides service handler hooks recording
+// lifecycle metrics, which can be served over HTTP in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdm12/goservices"
+)
+
+// Metrics implements service handler hooks recording
+// start and stop durations, crash and restart counts as
+// well as the current state of each service.
+// It also implements the http.Handler interface to serve
+// these metrics in the Prometheus text exposition format.
+type Metrics struct {
+	// Dependencies injected
+	namespace string
+	buckets   []float64
+	timeNow   func() time.Time
+
+	// Internal state
+	services map[string]*serviceMetrics
+	mutex    sync.RWMutex
+}
+
+// New creates a new Metrics hooks instance using the
+// settings given, and returns an error if any setting is
+// not valid.
+func New(settings Settings) (metrics *Metrics, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	buckets := make([]float64, len(settings.Buckets))
+	copy(buckets, settings.Buckets)
+
+	return &Metrics{
+		namespace: *settings.Namespace,
+		buckets:   buckets,
+		timeNow:   time.Now,
+		services:  make(map[string]*serviceMetrics),
+	}, nil
+}
+
+type serviceMetrics struct {
+	state          goservices.State
+	crashed        bool
+	startTime      time.Time
+	stopTime       time.Time
+	startDurations histogram
+	stopDurations  histogram
+	startFailures  uint64
+	stopFailures   uint64
+	crashes        uint64
+	restarts       uint64
+}
+
+// getService returns the metrics for the service given,
+// creating them if needed. The caller must hold the mutex.
+func (m *Metrics) getService(service string) *serviceMetrics {
+	metrics, ok := m.services[service]
+	if !ok {
+		metrics = &serviceMetrics{
+			state:          goservices.StateStopped,
+			startDurations: newHistogram(m.buckets),
+			stopDurations:  newHistogram(m.buckets),
+		}
+		m.services[service] = metrics
+	}
+	return metrics
+}
+
+// OnStart records the service start time and sets its state
+// to starting. If the service crashed previously, it is counted
+// as a restart.
+func (m *Metrics) OnStart(service string) {
+	now := m.timeNow()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	metrics := m.getService(service)
+	if metrics.crashed {
+		metrics.restarts++
+		metrics.crashed = false
+	}
+	metrics.state = goservices.StateStarting
+	metrics.startTime = now
+}
+
+// OnStarted records the service start duration and sets its
+// state to running, or to stopped if the service failed to start.
+func (m *Metrics) OnStarted(service string, err error) {
+	now := m.timeNow()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	metrics := m.getService(service)
+	if !metrics.startTime.IsZero() {
+		metrics.startDurations.observe(now.Sub(metrics.startTime).Seconds())
+	}
+	if err != nil {
+		metrics.startFailures++
+		metrics.state = goservices.StateStopped
+		return
+	}
+	metrics.state = goservices.StateRunning
+}
+
+// OnStop records the service stop time and sets its state
+// to stopping.
+func (m *Metrics) OnStop(service string) {
+	now := m.timeNow()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	metrics := m.getService(service)
+	metrics.state = goservices.StateStopping
+	metrics.stopTime = now
+}
+
+// OnStopped records the service stop duration and sets its
+// state to stopped.
+func (m *Metrics) OnStopped(service string, err error) {
+	now := m.timeNow()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	metrics := m.getService(service)
+	if !metrics.stopTime.IsZero() {
+		metrics.stopDurations.observe(now.Sub(metrics.stopTime).Seconds())
+	}
+	if err != nil {
+		metrics.stopFailures++
+	}
+	metrics.state = goservices.StateStopped
+}
+
+// OnCrash increments the service crash counter and sets its
+// state to crashed.
+func (m *Metrics) OnCrash(service string, _ error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	metrics := m.getService(service)
+	metrics.crashes++
+	metrics.crashed = true
+	metrics.state = goservices.StateCrashed
+}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	invalidNamespace, emptyNamespace := "1abc", ""
+	testCases := map[string]struct {
+		settings   Settings
+		namespace  string
+		buckets    []float64
+		errWrapped error
+		errMessage string
+	}{
+		"default settings": {
+			namespace: "goservices",
+			buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		"invalid namespace": {
+			settings: Settings{
+				Namespace: &invalidNamespace,
+			},
+			errWrapped: ErrNamespaceNotValid,
+			errMessage: "validating settings: namespace is not valid: " +
+				"\"1abc\" does not match ^[a-zA-Z_][a-zA-Z0-9_]*$",
+		},
+		"unsorted buckets": {
+			settings: Settings{
+				Buckets: []float64{2, 1},
+			},
+			errWrapped: ErrBucketsNotSorted,
+			errMessage: "validating settings: buckets are not sorted: [2 1]",
+		},
+		"empty namespace": {
+			settings: Settings{
+				Namespace: &emptyNamespace,
+				Buckets:   []float64{1},
+			},
+			buckets: []float64{1},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			metrics, err := New(testCase.settings)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				assert.Nil(t, metrics)
+				return
+			}
+			assert.Equal(t, testCase.namespace, metrics.namespace)
+			assert.Equal(t, testCase.buckets, metrics.buckets)
+		})
+	}
+}
+
+func Test_Metrics(t *testing.T) {
+	t.Parallel()
+
+	namespace := "test"
+	metrics, err := New(Settings{
+		Namespace: &namespace,
+		Buckets:   []float64{0.5, 1},
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(0, 0)
+	metrics.timeNow = func() time.Time { return now }
+	advance := func(d time.Duration) { now = now.Add(d) }
+
+	errTest := errors.New("test error")
+
+	metrics.OnStart("A")
+	advance(300 * time.Millisecond)
+	metrics.OnStarted("A", nil)
+	metrics.OnCrash("A", errTest)
+	metrics.OnStart("A")
+	advance(2 * time.Second)
+	metrics.OnStarted("A", nil)
+	metrics.OnStop("A")
+	advance(time.Second)
+	metrics.OnStopped("A", errTest)
+	metrics.OnStart(`B"\`)
+	metrics.OnStarted(`B"\`, errTest)
+	metrics.OnStart("C")
+	metrics.OnStarted("C", nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, request)
+
+	response := recorder.Result()
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8",
+		response.Header.Get("Content-Type"))
+
+	const expected = `# HELP test_service_start_duration_seconds Duration in seconds taken by services to start.
+# TYPE test_service_start_duration_seconds histogram
+test_service_start_duration_seconds_bucket{service="A",le="0.5"} 1
+test_service_start_duration_seconds_bucket{service="A",le="1"} 1
+test_service_start_duration_seconds_bucket{service="A",le="+Inf"} 2
+test_service_start_duration_seconds_sum{service="A"} 2.3
+test_service_start_duration_seconds_count{service="A"} 2
+test_service_start_duration_seconds_bucket{service="B\"\\",le="0.5"} 1
+test_service_start_duration_seconds_bucket{service="B\"\\",le="1"} 1
+test_service_start_duration_seconds_bucket{service="B\"\\",le="+Inf"} 1
+test_service_start_duration_seconds_sum{service="B\"\\"} 0
+test_service_start_duration_seconds_count{service="B\"\\"} 1
+test_service_start_duration_seconds_bucket{service="C",le="0.5"} 1
+test_service_start_duration_seconds_bucket{service="C",le="1"} 1
+test_service_start_duration_seconds_bucket{service="C",le="+Inf"} 1
+test_service_start_duration_seconds_sum{service="C"} 0
+test_service_start_duration_seconds_count{service="C"} 1
+# HELP test_service_stop_duration_seconds Duration in seconds taken by services to stop.
+# TYPE test_service_stop_duration_seconds histogram
+test_service_stop_duration_seconds_bucket{service="A",le="0.5"} 0
+test_service_stop_duration_seconds_bucket{service="A",le="1"} 1
+test_service_stop_duration_seconds_bucket{service="A",le="+Inf"} 1
+test_service_stop_duration_seconds_sum{service="A"} 1
+test_service_stop_duration_seconds_count{service="A"} 1
+test_service_stop_duration_seconds_bucket{service="B\"\\",le="0.5"} 0
+test_service_stop_duration_seconds_bucket{service="B\"\\",le="1"} 0
+test_service_stop_duration_seconds_bucket{service="B\"\\",le="+Inf"} 0
+test_service_stop_duration_seconds_sum{service="B\"\\"} 0
+test_service_stop_duration_seconds_count{service="B\"\\"} 0
+test_service_stop_duration_seconds_bucket{service="C",le="0.5"} 0
+test_service_stop_duration_seconds_bucket{service="C",le="1"} 0
+test_service_stop_duration_seconds_bucket{service="C",le="+Inf"} 0
+test_service_stop_duration_seconds_sum{service="C"} 0
+test_service_stop_duration_seconds_count{service="C"} 0
+# HELP test_service_start_failures_total Total number of service start failures.
+# TYPE test_service_start_failures_total counter
+test_service_start_failures_total{service="A"} 0
+test_service_start_failures_total{service="B\"\\"} 1
+test_service_start_failures_total{service="C"} 0
+# HELP test_service_stop_failures_total Total number of service stop failures.
+# TYPE test_service_stop_failures_total counter
+test_service_stop_failures_total{service="A"} 1
+test_service_stop_failures_total{service="B\"\\"} 0
+test_service_stop_failures_total{service="C"} 0
+# HELP test_service_crashes_total Total number of service crashes.
+# TYPE test_service_crashes_total counter
+test_service_crashes_total{service="A"} 1
+test_service_crashes_total{service="B\"\\"} 0
+test_service_crashes_total{service="C"} 0
+# HELP test_service_restarts_total Total number of service restarts after a crash.
+# TYPE test_service_restarts_total counter
+test_service_restarts_total{service="A"} 1
+test_service_restarts_total{service="B\"\\"} 0
+test_service_restarts_total{service="C"} 0
+# HELP test_service_state Current state of services, set to 1 for the current state.
+# TYPE test_service_state gauge
+test_service_state{service="A",state="stopped"} 1
+test_service_state{service="A",state="starting"} 0
+test_service_state{service="A",state="running"} 0
+test_service_state{service="A",state="stopping"} 0
+test_service_state{service="A",state="crashed"} 0
+test_service_state{service="B\"\\",state="stopped"} 1
+test_service_state{service="B\"\\",state="starting"} 0
+test_service_state{service="B\"\\",state="running"} 0
+test_service_state{service="B\"\\",state="stopping"} 0
+test_service_state{service="B\"\\",state="crashed"} 0
+test_service_state{service="C",state="stopped"} 0
+test_service_state{service="C",state="starting"} 0
+test_service_state{service="C",state="running"} 1
+test_service_state{service="C",state="stopping"} 0
+test_service_state{service="C",state="crashed"} 0
+`
+	assert.Equal(t, expected, recorder.Body.String())
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Settings is the settings for the metrics hooks.
+type Settings struct {
+	// Namespace is the prefix to use for all metric names.
+	// It defaults to "goservices" if left unset, and can
+	// be set to the empty string to have no prefix.
+	Namespace *string
+	// Buckets are the upper bounds in seconds of the start
+	// and stop duration histograms buckets. They must be sorted
+	// in increasing order. They default to
+	// 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5 and 10.
+	Buckets []float64
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Namespace == nil {
+		namespace := "goservices"
+		s.Namespace = &namespace
+	}
+
+	if s.Buckets == nil {
+		s.Buckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10} //nolint:mnd
+	}
+}
+
+var (
+	ErrNamespaceNotValid = errors.New("namespace is not valid")
+	ErrBucketsNotSorted  = errors.New("buckets are not sorted")
+)
+
+var regexNamespace = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	if *s.Namespace != "" && !regexNamespace.MatchString(*s.Namespace) {
+		return fmt.Errorf("%w: %q does not match %s",
+			ErrNamespaceNotValid, *s.Namespace, regexNamespace)
+	}
+
+	if !slices.IsSorted(s.Buckets) {
+		return fmt.Errorf("%w: %v", ErrBucketsNotSorted, s.Buckets)
+	}
+
+	return nil
+}
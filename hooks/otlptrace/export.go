@@ -0,0 +1,289 @@
+package otlptrace
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// exporter exports finished spans in batches
+// to an OTLP/HTTP JSON traces endpoint.
+type exporter struct {
+	// Dependencies injected
+	client        *http.Client
+	endpoint      string
+	headers       http.Header
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    uint
+	retryBackoff  time.Duration
+	closeTimeout  time.Duration
+
+	// Internal state
+	pending    []*span
+	mutex      sync.Mutex
+	batchReady chan struct{}
+	stopCtx    context.Context //nolint:containedctx
+	stop       context.CancelFunc
+	done       chan struct{}
+	closeOnce  sync.Once
+	closeErr   error
+}
+
+func newExporter(settings Settings) *exporter {
+	e := &exporter{
+		client:        settings.Client,
+		endpoint:      *settings.Endpoint,
+		headers:       settings.Headers.Clone(),
+		serviceName:   *settings.ServiceName,
+		batchSize:     settings.BatchSize,
+		flushInterval: settings.FlushInterval,
+		maxRetries:    *settings.MaxRetries,
+		retryBackoff:  settings.RetryBackoff,
+		closeTimeout:  settings.CloseTimeout,
+		batchReady:    make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	e.stopCtx, e.stop = context.WithCancel(context.Background())
+	go e.run()
+	return e
+}
+
+// add adds a finished span to the pending spans, and signals
+// the export goroutine if a full batch is ready.
+func (e *exporter) add(span *span) {
+	e.mutex.Lock()
+	e.pending = append(e.pending, span)
+	batchReady := len(e.pending) >= e.batchSize
+	e.mutex.Unlock()
+
+	if batchReady {
+		select {
+		case e.batchReady <- struct{}{}:
+		default: // already signaled
+		}
+	}
+}
+
+func (e *exporter) close() (err error) {
+	e.closeOnce.Do(func() {
+		e.stop()
+		<-e.done
+	})
+	return e.closeErr
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stopCtx.Done():
+			// The final flush, including its retries,
+			// is bounded by the close timeout.
+			ctx, cancel := context.WithTimeout(context.Background(), e.closeTimeout)
+			e.closeErr = e.flush(ctx)
+			cancel()
+			return
+		case <-ticker.C:
+		case <-e.batchReady:
+		}
+		// Export errors are only reported for the final flush,
+		// since spans export is best effort.
+		_ = e.flush(e.stopCtx)
+	}
+}
+
+// flush exports all the pending spans, in batches of
+// at most `batchSize` spans. If the context is canceled whilst
+// exporting, the spans not exported yet are put back in the
+// pending spans for the final flush. If the context deadline
+// is exceeded, the spans not exported yet are discarded.
+func (e *exporter) flush(ctx context.Context) (err error) {
+	e.mutex.Lock()
+	spans := e.pending
+	e.pending = nil
+	e.mutex.Unlock()
+
+	var errs []error
+	for len(spans) > 0 {
+		batchSize := min(len(spans), e.batchSize)
+		batchErr := e.export(ctx, spans[:batchSize])
+		if errors.Is(batchErr, context.Canceled) {
+			e.mutex.Lock()
+			e.pending = append(spans, e.pending...)
+			e.mutex.Unlock()
+			break
+		} else if errors.Is(batchErr, context.DeadlineExceeded) {
+			errs = append(errs, fmt.Errorf("exporting %d spans: %w", len(spans), ctx.Err()))
+			break
+		} else if batchErr != nil {
+			errs = append(errs, batchErr)
+		}
+		spans = spans[batchSize:]
+	}
+	return errors.Join(errs...)
+}
+
+var ErrExportFailed = errors.New("export failed")
+
+// export exports the spans given, retrying on retryable errors.
+// It returns the context error if the context is done whilst
+// posting or waiting to retry.
+func (e *exporter) export(ctx context.Context, spans []*span) (err error) {
+	body, err := json.Marshal(e.makeRequest(spans))
+	if err != nil {
+		return fmt.Errorf("encoding spans: %w", err)
+	}
+
+	backoff := e.retryBackoff
+	for attempt := uint(0); ; attempt++ {
+		var retryable bool
+		retryable, err = e.post(ctx, body)
+		if err == nil {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else if !retryable || attempt == e.maxRetries {
+			return fmt.Errorf("exporting %d spans: %w", len(spans), err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+func (e *exporter) post(ctx context.Context, body []byte) (retryable bool, err error) {
+	request, err := http.NewRequestWithContext(ctx,
+		http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+	for key, values := range e.headers {
+		request.Header[key] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		return false, nil
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		retryable = true
+	}
+	return retryable, fmt.Errorf("%w: %s", ErrExportFailed, response.Status)
+}
+
+// OTLP JSON encoding types, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	resource struct {
+		Attributes []attribute `json:"attributes"`
+	}
+	scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []jsonSpan `json:"spans"`
+	}
+	scope struct {
+		Name string `json:"name"`
+	}
+	jsonSpan struct {
+		TraceID           string      `json:"traceId"`
+		SpanID            string      `json:"spanId"`
+		ParentSpanID      string      `json:"parentSpanId,omitempty"`
+		Name              string      `json:"name"`
+		Kind              int         `json:"kind"`
+		StartTimeUnixNano string      `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+		Attributes        []attribute `json:"attributes"`
+		Status            status      `json:"status"`
+	}
+	attribute struct {
+		Key   string         `json:"key"`
+		Value attributeValue `json:"value"`
+	}
+	attributeValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+const (
+	spanKindInternal = 1
+	statusCodeOK     = 1
+	statusCodeError  = 2
+)
+
+func (e *exporter) makeRequest(spans []*span) exportRequest {
+	jsonSpans := make([]jsonSpan, len(spans))
+	for i, span := range spans {
+		jsonSpans[i] = jsonSpan{
+			TraceID:           hex.EncodeToString(span.traceID[:]),
+			SpanID:            hex.EncodeToString(span.spanID[:]),
+			Name:              span.phase + " " + span.service,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes: []attribute{
+				{Key: "goservices.service", Value: attributeValue{StringValue: span.service}},
+				{Key: "goservices.phase", Value: attributeValue{StringValue: span.phase}},
+			},
+			Status: status{Code: statusCodeOK},
+		}
+		if span.parentSpanID != [8]byte{} {
+			jsonSpans[i].ParentSpanID = hex.EncodeToString(span.parentSpanID[:])
+		}
+		if span.err != nil {
+			jsonSpans[i].Status = status{Code: statusCodeError, Message: span.err.Error()}
+		}
+	}
+
+	return exportRequest{
+		ResourceSpans: []resourceSpans{{
+			Resource: resource{
+				Attributes: []attribute{
+					{Key: "service.name", Value: attributeValue{StringValue: e.serviceName}},
+				},
+			},
+			ScopeSpans: []scopeSpans{{
+				Scope: scope{Name: "github.com/qdm12/goservices"},
+				Spans: jsonSpans,
+			}},
+		}},
+	}
+}
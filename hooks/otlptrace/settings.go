@@ -0,0 +1,125 @@
+package otlptrace
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Settings is the settings for the OTLP tracer.
+type Settings struct {
+	// Endpoint is the OTLP/HTTP traces endpoint URL.
+	// It defaults to "http://localhost:4318/v1/traces".
+	Endpoint *string
+	// ServiceName is the `service.name` resource attribute
+	// set on all spans exported. It defaults to "goservices".
+	ServiceName *string
+	// Headers are extra HTTP headers to set on each export
+	// request, for example for authentication.
+	Headers http.Header
+	// Client is the HTTP client used to export spans.
+	// It defaults to an HTTP client with a 10 seconds timeout.
+	Client *http.Client
+	// BatchSize is the number of finished spans triggering
+	// an export. It defaults to 64.
+	BatchSize int
+	// FlushInterval is the maximum duration a finished span
+	// is buffered for before being exported.
+	// It defaults to 5 seconds.
+	FlushInterval time.Duration
+	// MaxRetries is the maximum number of retries for an
+	// export request failing with a retryable error.
+	// It defaults to 3 and can be set to 0 to disable retries.
+	MaxRetries *uint
+	// RetryBackoff is the initial duration to wait before
+	// retrying an export request, doubled on each retry.
+	// It defaults to 1 second.
+	RetryBackoff time.Duration
+	// CloseTimeout is the maximum duration of the export of the
+	// remaining spans when closing, including retries. Spans not
+	// exported by then are discarded.
+	// It defaults to 5 seconds.
+	CloseTimeout time.Duration
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Endpoint == nil {
+		endpoint := "http://localhost:4318/v1/traces"
+		s.Endpoint = &endpoint
+	}
+
+	if s.ServiceName == nil {
+		serviceName := "goservices"
+		s.ServiceName = &serviceName
+	}
+
+	if s.Headers == nil {
+		s.Headers = http.Header{}
+	}
+
+	if s.Client == nil {
+		const defaultTimeout = 10 * time.Second
+		s.Client = &http.Client{Timeout: defaultTimeout}
+	}
+
+	if s.BatchSize == 0 {
+		const defaultBatchSize = 64
+		s.BatchSize = defaultBatchSize
+	}
+
+	if s.FlushInterval == 0 {
+		const defaultFlushInterval = 5 * time.Second
+		s.FlushInterval = defaultFlushInterval
+	}
+
+	if s.MaxRetries == nil {
+		const defaultMaxRetries = 3
+		maxRetries := uint(defaultMaxRetries)
+		s.MaxRetries = &maxRetries
+	}
+
+	if s.RetryBackoff == 0 {
+		s.RetryBackoff = time.Second
+	}
+
+	if s.CloseTimeout == 0 {
+		const defaultCloseTimeout = 5 * time.Second
+		s.CloseTimeout = defaultCloseTimeout
+	}
+}
+
+var (
+	ErrEndpointNotValid      = errors.New("endpoint is not valid")
+	ErrBatchSizeNotValid     = errors.New("batch size is not valid")
+	ErrFlushIntervalNotValid = errors.New("flush interval is not valid")
+	ErrRetryBackoffNotValid  = errors.New("retry backoff is not valid")
+	ErrCloseTimeoutNotValid  = errors.New("close timeout is not valid")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	endpoint, err := url.Parse(*s.Endpoint)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrEndpointNotValid, err)
+	} else if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q must be http or https",
+			ErrEndpointNotValid, endpoint.Scheme)
+	}
+
+	switch {
+	case s.BatchSize < 0:
+		return fmt.Errorf("%w: %d must be positive", ErrBatchSizeNotValid, s.BatchSize)
+	case s.FlushInterval < 0:
+		return fmt.Errorf("%w: %s must be positive", ErrFlushIntervalNotValid, s.FlushInterval)
+	case s.RetryBackoff < 0:
+		return fmt.Errorf("%w: %s must be positive", ErrRetryBackoffNotValid, s.RetryBackoff)
+	case s.CloseTimeout < 0:
+		return fmt.Errorf("%w: %s must be positive", ErrCloseTimeoutNotValid, s.CloseTimeout)
+	}
+
+	return nil
+}
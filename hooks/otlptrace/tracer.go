@@ -0,0 +1,159 @@
+// Package otlptrace provides service handler hooks building
+// trace spans from service start and stop events, and
+// exporting them as OTLP/HTTP JSON to a trace collector.
+package otlptrace
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdm12/goservices/hooks/internal/nested"
+)
+
+// Tracer records spans for service start and stop phases,
+// and exports them in batches to an OTLP/HTTP collector.
+//
+// The Tracer itself implements the service handler hooks,
+// recording spans without parent span. Hooks recording spans
+// nested under a composite service span can be obtained with
+// the `Hooks` method, and a root service can be wrapped with
+// the `Wrap` method to record spans for its own start and stop.
+type Tracer struct {
+	*rootHooks
+
+	exporter *exporter
+	timeNow  func() time.Time
+
+	// openSpans maps a phase and service name to
+	// the span started and not yet ended.
+	openSpans map[nested.Key]*span
+	mutex     sync.Mutex
+}
+
+// rootHooks records spans without parent span.
+type rootHooks = nested.Hooks
+
+// New creates a new tracer using the settings given, and
+// returns an error if any setting is not valid.
+// The Close method must be called to export the remaining
+// spans and release resources.
+func New(settings Settings) (tracer *Tracer, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	tracer = &Tracer{
+		exporter:  newExporter(settings),
+		timeNow:   time.Now,
+		openSpans: make(map[nested.Key]*span),
+	}
+	tracer.rootHooks = nested.NewHooks((*phases)(tracer), "")
+	return tracer, nil
+}
+
+// Close exports the remaining finished spans, for at most the
+// close timeout, and stops the background exporter. Spans not
+// yet ended are discarded.
+// Hook methods must not be called after Close is called.
+func (t *Tracer) Close() (err error) {
+	return t.exporter.close()
+}
+
+type span struct {
+	traceID      [16]byte
+	spanID       [8]byte
+	parentSpanID [8]byte
+	phase        string
+	service      string
+	start        time.Time
+	end          time.Time
+	err          error
+}
+
+// Hooks is the type of service handler hooks recording
+// spans nested under the span of a parent service.
+type Hooks = nested.Hooks
+
+// Hooks returns service handler hooks recording spans as
+// children of the span of the parent service for the same phase,
+// if the parent span is currently recorded. This should typically
+// be used as hooks for a composite service such as a sequence or
+// group, with `parent` set to the composite service string.
+func (t *Tracer) Hooks(parent string) *Hooks {
+	return nested.NewHooks((*phases)(t), parent)
+}
+
+// phases records spans of the tracer for the hooks.
+type phases Tracer
+
+func (p *phases) Begin(phase, parent, service string) {
+	now := p.timeNow()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.openSpans[nested.Key{Phase: phase, Service: service}] = p.newSpan(phase, parent, service, now)
+}
+
+// newSpan creates a new span as child of the parent span for
+// the same phase if it is open, otherwise as a root span with
+// a new trace ID. The caller must hold the mutex.
+func (p *phases) newSpan(phase, parent, service string, start time.Time) *span {
+	newSpan := &span{
+		spanID:  newSpanID(),
+		phase:   phase,
+		service: service,
+		start:   start,
+	}
+
+	parentSpan, ok := nested.Parent(p.openSpans, phase, parent)
+	if ok {
+		newSpan.traceID = parentSpan.traceID
+		newSpan.parentSpanID = parentSpan.spanID
+	} else {
+		newSpan.traceID = newTraceID()
+	}
+	return newSpan
+}
+
+func (p *phases) End(phase, service string, err error) {
+	now := p.timeNow()
+
+	p.mutex.Lock()
+	key := nested.Key{Phase: phase, Service: service}
+	span, ok := p.openSpans[key]
+	delete(p.openSpans, key)
+	p.mutex.Unlock()
+
+	if !ok {
+		return
+	}
+	span.end = now
+	span.err = err
+	p.exporter.add(span)
+}
+
+func (p *phases) Crash(parent, service string, err error) {
+	now := p.timeNow()
+
+	p.mutex.Lock()
+	span := p.newSpan(nested.PhaseCrash, parent, service, now)
+	p.mutex.Unlock()
+
+	span.end = now
+	span.err = err
+	p.exporter.add(span)
+}
+
+func newTraceID() (id [16]byte) {
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() (id [8]byte) {
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,218 @@
+package otlptrace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/qdm12/goservices/internal/servicetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type collector struct {
+	mutex     sync.Mutex
+	requests  []exportRequest
+	failFirst int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.failFirst > 0 {
+		c.failFirst--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var request exportRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil || r.Header.Get("Content-Type") != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.requests = append(c.requests, request)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *collector) spans() (spans []jsonSpan) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, request := range c.requests {
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				spans = append(spans, scopeSpans.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+func Test_Tracer(t *testing.T) {
+	t.Parallel()
+
+	collector := &collector{failFirst: 1}
+	server := httptest.NewServer(collector)
+	t.Cleanup(server.Close)
+
+	endpoint, serviceName := server.URL, "test"
+	tracer, err := New(Settings{
+		Endpoint:      &endpoint,
+		ServiceName:   &serviceName,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	serviceA := servicetest.New("A", nil)
+	serviceB := servicetest.New("B", nil)
+	group, err := goservices.NewGroup(goservices.GroupSettings{
+		Name:     "g",
+		Services: []goservices.Service{serviceA, serviceB},
+		Hooks:    tracer.Hooks("group g"),
+	})
+	require.NoError(t, err)
+	serviceC := servicetest.New("C", nil)
+	sequence, err := goservices.NewSequence(goservices.SequenceSettings{
+		Name:          "s",
+		ServicesStart: []goservices.Service{group, serviceC},
+		ServicesStop:  []goservices.Service{serviceC, group},
+		Hooks:         tracer.Hooks("sequence s"),
+	})
+	require.NoError(t, err)
+	root := tracer.Wrap(sequence)
+
+	_, err = root.Start(context.Background())
+	require.NoError(t, err)
+	err = root.Stop()
+	require.NoError(t, err)
+	tracer.OnCrash("D", errors.New("test error"))
+
+	err = tracer.Close()
+	require.NoError(t, err)
+
+	spans := collector.spans()
+	require.Len(t, spans, 11)
+	spanNameToSpan := make(map[string]jsonSpan, len(spans))
+	for _, span := range spans {
+		spanNameToSpan[span.Name] = span
+	}
+
+	type expectation struct {
+		parent string
+		status status
+	}
+	expectations := map[string]expectation{
+		"start sequence s": {status: status{Code: statusCodeOK}},
+		"start group g":    {parent: "start sequence s", status: status{Code: statusCodeOK}},
+		"start A":          {parent: "start group g", status: status{Code: statusCodeOK}},
+		"start B":          {parent: "start group g", status: status{Code: statusCodeOK}},
+		"start C":          {parent: "start sequence s", status: status{Code: statusCodeOK}},
+		"stop sequence s":  {status: status{Code: statusCodeOK}},
+		"stop C":           {parent: "stop sequence s", status: status{Code: statusCodeOK}},
+		"stop group g":     {parent: "stop sequence s", status: status{Code: statusCodeOK}},
+		"stop A":           {parent: "stop group g", status: status{Code: statusCodeOK}},
+		"stop B":           {parent: "stop group g", status: status{Code: statusCodeOK}},
+		"crash D":          {status: status{Code: statusCodeError, Message: "test error"}},
+	}
+	for name, expected := range expectations {
+		span, ok := spanNameToSpan[name]
+		require.Truef(t, ok, "span %q not found", name)
+		assert.Equalf(t, expected.status, span.Status, "span %q", name)
+		if expected.parent == "" {
+			assert.Emptyf(t, span.ParentSpanID, "span %q", name)
+			continue
+		}
+		parent := spanNameToSpan[expected.parent]
+		assert.Equalf(t, parent.SpanID, span.ParentSpanID, "span %q", name)
+		assert.Equalf(t, parent.TraceID, span.TraceID, "span %q", name)
+	}
+	assert.NotEqual(t, spanNameToSpan["start sequence s"].TraceID,
+		spanNameToSpan["stop sequence s"].TraceID)
+}
+
+func Test_Tracer_exportFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := server.URL
+	tracer, err := New(Settings{
+		Endpoint:      &endpoint,
+		FlushInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	tracer.OnStart("A")
+	tracer.OnStarted("A", nil)
+
+	err = tracer.Close()
+	assert.ErrorIs(t, err, ErrExportFailed)
+	assert.EqualError(t, err, "exporting 1 spans: export failed: 400 Bad Request")
+}
+
+func Test_Tracer_closeDuringRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	collector := &collector{failFirst: 1}
+	server := httptest.NewServer(collector)
+	t.Cleanup(server.Close)
+
+	endpoint := server.URL
+	tracer, err := New(Settings{
+		Endpoint:      &endpoint,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Hour,
+	})
+	require.NoError(t, err)
+
+	tracer.OnStart("A")
+	tracer.OnStarted("A", nil)
+
+	// Wait for the first export to fail and its retry to be pending.
+	assert.Eventually(t, func() bool {
+		collector.mutex.Lock()
+		defer collector.mutex.Unlock()
+		return collector.failFirst == 0
+	}, time.Second, time.Millisecond)
+
+	err = tracer.Close()
+	require.NoError(t, err)
+	assert.Len(t, collector.spans(), 1)
+}
+
+func Test_Tracer_closeTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := server.URL
+	tracer, err := New(Settings{
+		Endpoint:      &endpoint,
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Hour,
+		CloseTimeout:  10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	tracer.OnStart("A")
+	tracer.OnStarted("A", nil)
+
+	start := time.Now()
+	err = tracer.Close()
+	assert.Less(t, time.Since(start), time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualError(t, err, "exporting 1 spans: context deadline exceeded")
+}
@@ -0,0 +1,19 @@
+package otlptrace
+
+import (
+	"github.com/qdm12/goservices"
+	"github.com/qdm12/goservices/hooks/internal/nested"
+)
+
+// Service is the type of service wrapping a service
+// to record spans for its start and stop calls.
+type Service = nested.Service
+
+// Wrap returns a service wrapping the given service to record
+// spans for its own start and stop calls. This is typically used
+// on the root service of the tree, for its start and stop spans to
+// be the parent spans of spans recorded by hooks obtained with
+// `tracer.Hooks(service.String())`.
+func (t *Tracer) Wrap(service goservices.Service) *Service {
+	return nested.Wrap(service, t)
+}
@@ -0,0 +1,68 @@
+package statsd
+
+import (
+	"io"
+	"time"
+)
+
+// send reads metric lines from the queue and aggregates them
+// in packets of at most maxPacketSize bytes, separated by newlines.
+// A packet is written when it is full, when the flush interval
+// elapses or when the stop channel is closed, in which case lines
+// still queued are sent first. Write errors are ignored
+// since the StatsD agent may not be listening.
+func send(writer io.Writer, queue <-chan string,
+	maxPacketSize int, flushInterval time.Duration,
+	stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	packet := make([]byte, 0, maxPacketSize)
+	flush := func() {
+		if len(packet) == 0 {
+			return
+		}
+		_, _ = writer.Write(packet)
+		packet = packet[:0]
+	}
+
+	add := func(line string) {
+		if len(line) > maxPacketSize {
+			// Line cannot fit in a packet, drop it.
+			return
+		}
+
+		extraLength := len(line)
+		if len(packet) > 0 {
+			extraLength++ // newline separator
+		}
+		if len(packet)+extraLength > maxPacketSize {
+			flush()
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			flush()
+		case line := <-queue:
+			add(line)
+		case <-stop:
+			for {
+				select {
+				case line := <-queue:
+					add(line)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package statsd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type packetsWriter struct {
+	packets []string
+}
+
+func (w *packetsWriter) Write(b []byte) (n int, err error) {
+	w.packets = append(w.packets, string(b))
+	return len(b), nil
+}
+
+func Test_send(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		lines         []string
+		maxPacketSize int
+		packets       []string
+	}{
+		"no line": {
+			maxPacketSize: 10,
+		},
+		"single line": {
+			lines:         []string{"a:1|c"},
+			maxPacketSize: 10,
+			packets:       []string{"a:1|c"},
+		},
+		"lines aggregated": {
+			lines:         []string{"a:1|c", "b:1|c", "c:1|c"},
+			maxPacketSize: 11,
+			packets:       []string{"a:1|c\nb:1|c", "c:1|c"},
+		},
+		"line too long dropped": {
+			lines:         []string{"a:1|c", "bbbbbbbbbbbb:1|c", "c:1|c"},
+			maxPacketSize: 11,
+			packets:       []string{"a:1|c\nc:1|c"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			writer := &packetsWriter{}
+			queue := make(chan string, len(testCase.lines))
+			for _, line := range testCase.lines {
+				queue <- line
+			}
+			stop := make(chan struct{})
+			close(stop)
+			done := make(chan struct{})
+
+			send(writer, queue, testCase.maxPacketSize, time.Hour, stop, done)
+
+			<-done
+			assert.Equal(t, testCase.packets, writer.packets)
+		})
+	}
+}
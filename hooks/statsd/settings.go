@@ -0,0 +1,136 @@
+package statsd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// TagFormat is the format to use to encode tags in
+// the StatsD metric lines.
+type TagFormat string
+
+const (
+	// TagFormatNone does not use tags and instead inserts the
+	// service name in the metric name, as plain StatsD does not
+	// support tags. Extra tags from the settings are ignored.
+	TagFormatNone TagFormat = "none"
+	// TagFormatDogStatsD appends tags to the metric line in the
+	// DogStatsD format, for example `name:1|c|#service:a,env:prod`.
+	TagFormatDogStatsD TagFormat = "dogstatsd"
+	// TagFormatInflux appends tags to the metric name in the
+	// InfluxDB/Telegraf format, for example `name,service=a,env=prod:1|c`.
+	TagFormatInflux TagFormat = "influx"
+)
+
+// Settings is the settings for the StatsD hooks.
+type Settings struct {
+	// Address is the UDP address of the StatsD agent.
+	// It defaults to "127.0.0.1:8125" if left unset.
+	Address *string
+	// Prefix is the prefix prepended to each metric name.
+	// It defaults to "goservices." if left unset, and can
+	// be set to the empty string to have no prefix.
+	Prefix *string
+	// TagFormat is the format to use for tags.
+	// It defaults to TagFormatNone if left unset.
+	TagFormat TagFormat
+	// Tags are extra tags added to each metric line, in the
+	// form key:value. They are ignored for TagFormatNone.
+	Tags []string
+	// MaxPacketSize is the maximum size in bytes of each UDP
+	// packet sent, in which multiple metric lines are aggregated.
+	// It defaults to 1432 bytes, which fits in a typical
+	// 1500 bytes Ethernet MTU.
+	MaxPacketSize int
+	// FlushInterval is the maximum duration a metric line
+	// is buffered for before being sent.
+	// It defaults to 1 second.
+	FlushInterval time.Duration
+	// QueueSize is the number of metric lines that can be
+	// queued before new metric lines are dropped, so hooks never
+	// block the calling service management code.
+	// It defaults to 1024.
+	QueueSize int
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Address == nil {
+		address := "127.0.0.1:8125"
+		s.Address = &address
+	}
+
+	if s.Prefix == nil {
+		prefix := "goservices."
+		s.Prefix = &prefix
+	}
+
+	if s.TagFormat == "" {
+		s.TagFormat = TagFormatNone
+	}
+
+	if s.MaxPacketSize == 0 {
+		const defaultMaxPacketSize = 1432
+		s.MaxPacketSize = defaultMaxPacketSize
+	}
+
+	if s.FlushInterval == 0 {
+		s.FlushInterval = time.Second
+	}
+
+	if s.QueueSize == 0 {
+		const defaultQueueSize = 1024
+		s.QueueSize = defaultQueueSize
+	}
+}
+
+var (
+	ErrTagFormatNotValid     = errors.New("tag format is not valid")
+	ErrTagNotValid           = errors.New("tag is not valid")
+	ErrMaxPacketSizeTooSmall = errors.New("maximum packet size is too small")
+	ErrFlushIntervalNotValid = errors.New("flush interval is not valid")
+	ErrQueueSizeNotValid     = errors.New("queue size is not valid")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	_, err = net.ResolveUDPAddr("udp", *s.Address)
+	if err != nil {
+		return fmt.Errorf("address is not valid: %w", err)
+	}
+
+	switch s.TagFormat {
+	case TagFormatNone, TagFormatDogStatsD, TagFormatInflux:
+	default:
+		return fmt.Errorf("%w: %q", ErrTagFormatNotValid, s.TagFormat)
+	}
+
+	for _, tag := range s.Tags {
+		key, value, ok := strings.Cut(tag, ":")
+		if !ok || key == "" || value == "" ||
+			sanitize(key) != key || sanitize(value) != value {
+			return fmt.Errorf("%w: %q must be in the form key:value "+
+				"and not contain any of %q", ErrTagNotValid, tag, reservedCharacters)
+		}
+	}
+
+	const minPacketSize = 64
+	if s.MaxPacketSize < minPacketSize {
+		return fmt.Errorf("%w: %d must be at least %d",
+			ErrMaxPacketSizeTooSmall, s.MaxPacketSize, minPacketSize)
+	}
+
+	if s.FlushInterval < 0 {
+		return fmt.Errorf("%w: %s must be positive", ErrFlushIntervalNotValid, s.FlushInterval)
+	}
+
+	if s.QueueSize < 0 {
+		return fmt.Errorf("%w: %d must be positive", ErrQueueSizeNotValid, s.QueueSize)
+	}
+
+	return nil
+}
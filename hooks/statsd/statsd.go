@@ -0,0 +1,235 @@
+// Package statsd provides service handler hooks sending
+// lifecycle metrics to a StatsD or DogStatsD agent over UDP.
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatsD implements service handler hooks sending counters
+// and timers for each service event to a StatsD agent.
+// Hook methods never block: metric lines are queued and
+// sent in the background, and dropped if the queue is full.
+type StatsD struct {
+	// Dependencies injected
+	prefix    string
+	tagFormat TagFormat
+	tags      []string
+	timeNow   func() time.Time
+
+	// Internal state
+	queue      chan string
+	dropped    atomic.Uint64
+	startTimes map[string]time.Time
+	stopTimes  map[string]time.Time
+	crashed    map[string]struct{}
+	mutex      sync.Mutex
+	closed     bool
+	closeMutex sync.RWMutex
+	stop       chan<- struct{}
+	done       <-chan struct{}
+	conn       net.Conn
+}
+
+// New creates a new StatsD hooks instance using the settings
+// given. It returns an error if any setting is not valid or if
+// the UDP socket cannot be created. Note the StatsD agent does
+// not need to be reachable for this to succeed.
+// The Close method must be called to flush and release resources.
+func New(settings Settings) (statsd *StatsD, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	address, err := net.ResolveUDPAddr("udp", *settings.Address)
+	if err != nil {
+		return nil, fmt.Errorf("resolving udp address: %w", err)
+	}
+
+	conn, err := net.DialUDP("udp", nil, address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing udp address: %w", err)
+	}
+
+	tags := make([]string, len(settings.Tags))
+	copy(tags, settings.Tags)
+
+	queue := make(chan string, settings.QueueSize)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	statsd = &StatsD{
+		prefix:     *settings.Prefix,
+		tagFormat:  settings.TagFormat,
+		tags:       tags,
+		timeNow:    time.Now,
+		queue:      queue,
+		startTimes: make(map[string]time.Time),
+		stopTimes:  make(map[string]time.Time),
+		crashed:    make(map[string]struct{}),
+		stop:       stop,
+		done:       done,
+		conn:       conn,
+	}
+
+	go send(conn, queue, settings.MaxPacketSize, settings.FlushInterval, stop, done)
+
+	return statsd, nil
+}
+
+// Close flushes queued metric lines and closes the UDP socket.
+// Metric lines from hook methods called after Close are dropped.
+func (s *StatsD) Close() (err error) {
+	s.closeMutex.Lock()
+	if s.closed {
+		s.closeMutex.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.closeMutex.Unlock()
+
+	close(s.stop)
+	<-s.done
+	return s.conn.Close()
+}
+
+// Dropped returns the number of metric lines dropped
+// because the queue was full or Close was called.
+func (s *StatsD) Dropped() (count uint64) {
+	return s.dropped.Load()
+}
+
+// OnStart sends a start counter for the service, and a restart
+// counter if the service crashed previously.
+func (s *StatsD) OnStart(service string) {
+	s.mutex.Lock()
+	s.startTimes[service] = s.timeNow()
+	_, crashed := s.crashed[service]
+	delete(s.crashed, service)
+	s.mutex.Unlock()
+
+	s.enqueue("service.start", service, "1|c")
+	if crashed {
+		s.enqueue("service.restart", service, "1|c")
+	}
+}
+
+// OnStarted sends a timer of the service start duration, and a
+// start failure counter if the service failed to start.
+func (s *StatsD) OnStarted(service string, err error) {
+	s.mutex.Lock()
+	startTime, ok := s.startTimes[service]
+	delete(s.startTimes, service)
+	s.mutex.Unlock()
+
+	if ok {
+		s.enqueue("service.started", service, formatTimer(s.timeNow().Sub(startTime)))
+	}
+	if err != nil {
+		s.enqueue("service.start_failure", service, "1|c")
+	}
+}
+
+// OnStop sends a stop counter for the service.
+func (s *StatsD) OnStop(service string) {
+	s.mutex.Lock()
+	s.stopTimes[service] = s.timeNow()
+	s.mutex.Unlock()
+
+	s.enqueue("service.stop", service, "1|c")
+}
+
+// OnStopped sends a timer of the service stop duration, and a
+// stop failure counter if the service failed to stop.
+func (s *StatsD) OnStopped(service string, err error) {
+	s.mutex.Lock()
+	stopTime, ok := s.stopTimes[service]
+	delete(s.stopTimes, service)
+	s.mutex.Unlock()
+
+	if ok {
+		s.enqueue("service.stopped", service, formatTimer(s.timeNow().Sub(stopTime)))
+	}
+	if err != nil {
+		s.enqueue("service.stop_failure", service, "1|c")
+	}
+}
+
+// OnCrash sends a crash counter for the service.
+func (s *StatsD) OnCrash(service string, _ error) {
+	s.mutex.Lock()
+	s.crashed[service] = struct{}{}
+	s.mutex.Unlock()
+
+	s.enqueue("service.crash", service, "1|c")
+}
+
+func formatTimer(duration time.Duration) string {
+	const msPerSecond = 1000
+	milliseconds := duration.Seconds() * msPerSecond
+	return strconv.FormatFloat(milliseconds, 'f', -1, 64) + "|ms"
+}
+
+// enqueue formats the metric line and queues it without blocking.
+// The line is dropped if the queue is full or if Close was called.
+func (s *StatsD) enqueue(name, service, valueAndType string) {
+	line := s.formatLine(name, sanitize(service), valueAndType)
+
+	s.closeMutex.RLock()
+	defer s.closeMutex.RUnlock()
+	if s.closed {
+		s.dropped.Add(1)
+		return
+	}
+
+	select {
+	case s.queue <- line:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *StatsD) formatLine(name, service, valueAndType string) string {
+	switch s.tagFormat {
+	case TagFormatDogStatsD:
+		tags := append([]string{"service:" + service}, s.tags...)
+		return s.prefix + name + ":" + valueAndType + "|#" + strings.Join(tags, ",")
+	case TagFormatInflux:
+		var builder strings.Builder
+		builder.WriteString(s.prefix + name + ",service=" + service)
+		for _, tag := range s.tags {
+			builder.WriteString("," + strings.Replace(tag, ":", "=", 1))
+		}
+		builder.WriteString(":" + valueAndType)
+		return builder.String()
+	case TagFormatNone:
+		fallthrough
+	default:
+		// Insert the service name before the last metric name part,
+		// for example service.<name>.start
+		before, after, _ := strings.Cut(name, ".")
+		return s.prefix + before + "." + service + "." + after + ":" + valueAndType
+	}
+}
+
+// reservedCharacters are characters having a meaning in
+// the StatsD protocol and its tag format extensions.
+const reservedCharacters = ":|@#,= \t\r\n"
+
+// sanitize replaces characters reserved by the StatsD protocol
+// with underscores.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(reservedCharacters, r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,201 @@
+package statsd
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings   Settings
+		errWrapped error
+		errMessage string
+	}{
+		"invalid tag format": {
+			settings: Settings{
+				TagFormat: "x",
+			},
+			errWrapped: ErrTagFormatNotValid,
+			errMessage: `validating settings: tag format is not valid: "x"`,
+		},
+		"invalid tag": {
+			settings: Settings{
+				Tags: []string{"env|prod"},
+			},
+			errWrapped: ErrTagNotValid,
+			errMessage: `validating settings: tag is not valid: "env|prod" must be ` +
+				`in the form key:value and not contain any of ":|@#,= \t\r\n"`,
+		},
+		"max packet size too small": {
+			settings: Settings{
+				MaxPacketSize: 1,
+			},
+			errWrapped: ErrMaxPacketSizeTooSmall,
+			errMessage: "validating settings: maximum packet size is too small: 1 must be at least 64",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			statsd, err := New(testCase.settings)
+
+			assert.Nil(t, statsd)
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.EqualError(t, err, testCase.errMessage)
+		})
+	}
+}
+
+func Test_StatsD(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		tagFormat TagFormat
+		lines     []string
+	}{
+		"no tags": {
+			tagFormat: TagFormatNone,
+			lines: []string{
+				"test.service.A_B.start:1|c",
+				"test.service.A_B.started:1500|ms",
+				"test.service.A_B.crash:1|c",
+				"test.service.A_B.start:1|c",
+				"test.service.A_B.restart:1|c",
+				"test.service.A_B.started:0|ms",
+				"test.service.A_B.start_failure:1|c",
+				"test.service.A_B.stop:1|c",
+				"test.service.A_B.stopped:250|ms",
+				"test.service.A_B.stop_failure:1|c",
+			},
+		},
+		"dogstatsd": {
+			tagFormat: TagFormatDogStatsD,
+			lines: []string{
+				"test.service.start:1|c|#service:A_B,env:prod",
+				"test.service.started:1500|ms|#service:A_B,env:prod",
+				"test.service.crash:1|c|#service:A_B,env:prod",
+				"test.service.start:1|c|#service:A_B,env:prod",
+				"test.service.restart:1|c|#service:A_B,env:prod",
+				"test.service.started:0|ms|#service:A_B,env:prod",
+				"test.service.start_failure:1|c|#service:A_B,env:prod",
+				"test.service.stop:1|c|#service:A_B,env:prod",
+				"test.service.stopped:250|ms|#service:A_B,env:prod",
+				"test.service.stop_failure:1|c|#service:A_B,env:prod",
+			},
+		},
+		"influx": {
+			tagFormat: TagFormatInflux,
+			lines: []string{
+				"test.service.start,service=A_B,env=prod:1|c",
+				"test.service.started,service=A_B,env=prod:1500|ms",
+				"test.service.crash,service=A_B,env=prod:1|c",
+				"test.service.start,service=A_B,env=prod:1|c",
+				"test.service.restart,service=A_B,env=prod:1|c",
+				"test.service.started,service=A_B,env=prod:0|ms",
+				"test.service.start_failure,service=A_B,env=prod:1|c",
+				"test.service.stop,service=A_B,env=prod:1|c",
+				"test.service.stopped,service=A_B,env=prod:250|ms",
+				"test.service.stop_failure,service=A_B,env=prod:1|c",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = agent.Close()
+			})
+
+			address, prefix := agent.LocalAddr().String(), "test."
+			statsd, err := New(Settings{
+				Address:       &address,
+				Prefix:        &prefix,
+				TagFormat:     testCase.tagFormat,
+				Tags:          []string{"env:prod"},
+				FlushInterval: time.Hour,
+			})
+			require.NoError(t, err)
+
+			now := time.Unix(0, 0)
+			statsd.timeNow = func() time.Time { return now }
+
+			errTest := errors.New("test error")
+			const service = "A B"
+			statsd.OnStart(service)
+			now = now.Add(1500 * time.Millisecond)
+			statsd.OnStarted(service, nil)
+			statsd.OnCrash(service, errTest)
+			statsd.OnStart(service)
+			statsd.OnStarted(service, errTest)
+			statsd.OnStop(service)
+			now = now.Add(250 * time.Millisecond)
+			statsd.OnStopped(service, errTest)
+
+			err = statsd.Close()
+			require.NoError(t, err)
+
+			var lines []string
+			buffer := make([]byte, 2048)
+			for len(lines) < len(testCase.lines) {
+				err = agent.SetReadDeadline(time.Now().Add(time.Second))
+				require.NoError(t, err)
+				n, _, err := agent.ReadFrom(buffer)
+				require.NoError(t, err)
+				lines = append(lines, strings.Split(string(buffer[:n]), "\n")...)
+			}
+
+			assert.Equal(t, testCase.lines, lines)
+			assert.Zero(t, statsd.Dropped())
+		})
+	}
+}
+
+func Test_StatsD_nonBlocking(t *testing.T) {
+	t.Parallel()
+
+	statsd := &StatsD{
+		timeNow:    time.Now,
+		queue:      make(chan string, 1),
+		startTimes: make(map[string]time.Time),
+		stopTimes:  make(map[string]time.Time),
+		crashed:    make(map[string]struct{}),
+	}
+
+	statsd.OnStop("A")
+	statsd.OnStop("B")
+
+	assert.Equal(t, uint64(1), statsd.Dropped())
+}
+
+func Test_StatsD_hookAfterClose(t *testing.T) {
+	t.Parallel()
+
+	address := "127.0.0.1:1"
+	statsd, err := New(Settings{
+		Address: &address,
+	})
+	require.NoError(t, err)
+
+	err = statsd.Close()
+	require.NoError(t, err)
+
+	statsd.OnStart("A")
+
+	assert.Equal(t, uint64(1), statsd.Dropped())
+	err = statsd.Close()
+	assert.NoError(t, err)
+}
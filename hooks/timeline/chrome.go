@@ -0,0 +1,108 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/qdm12/goservices/hooks/internal/nested"
+)
+
+// chromeTrace is the Chrome trace event format JSON object, see
+// https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+type chromeTrace struct {
+	TraceEvents     []chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+type chromeEvent struct {
+	Name      string            `json:"name"`
+	Category  string            `json:"cat,omitempty"`
+	Phase     string            `json:"ph"`
+	Timestamp float64           `json:"ts"`
+	Duration  float64           `json:"dur,omitempty"`
+	PID       int               `json:"pid"`
+	TID       int               `json:"tid"`
+	Scope     string            `json:"s,omitempty"`
+	Args      map[string]string `json:"args,omitempty"`
+}
+
+const (
+	chromePhaseComplete = "X"
+	chromePhaseInstant  = "i"
+	chromePhaseMetadata = "M"
+)
+
+// WriteChromeTrace writes the events recorded in the Chrome
+// trace event JSON format, which can be viewed with Perfetto
+// or chrome://tracing. Each service is shown on its own thread
+// track, so concurrent starts and stops appear side by side.
+// Events not yet ended are not written.
+func (r *Recorder) WriteChromeTrace(w io.Writer) (err error) {
+	r.mutex.Lock()
+	trace := r.makeChromeTrace()
+	r.mutex.Unlock()
+
+	encoder := json.NewEncoder(w)
+	err = encoder.Encode(trace)
+	if err != nil {
+		return fmt.Errorf("encoding chrome trace: %w", err)
+	}
+	return nil
+}
+
+// makeChromeTrace creates the Chrome trace from the events
+// recorded. The caller must hold the mutex.
+func (r *Recorder) makeChromeTrace() chromeTrace {
+	const pid = 1
+	trace := chromeTrace{
+		TraceEvents:     make([]chromeEvent, 0, len(r.events)),
+		DisplayTimeUnit: "ms",
+	}
+	serviceToTID := make(map[string]int)
+
+	for _, event := range r.events {
+		if !event.ended {
+			continue
+		}
+
+		tid, ok := serviceToTID[event.service]
+		if !ok {
+			tid = len(serviceToTID) + 1
+			serviceToTID[event.service] = tid
+			trace.TraceEvents = append(trace.TraceEvents, chromeEvent{
+				Name:  "thread_name",
+				Phase: chromePhaseMetadata,
+				PID:   pid,
+				TID:   tid,
+				Args:  map[string]string{"name": event.service},
+			})
+		}
+
+		chromeEvent := chromeEvent{
+			Name:      event.name(),
+			Category:  event.phase,
+			Phase:     chromePhaseComplete,
+			Timestamp: microseconds(event.begin.Sub(r.origin)),
+			Duration:  microseconds(event.duration()),
+			PID:       pid,
+			TID:       tid,
+		}
+		if event.phase == nested.PhaseCrash {
+			chromeEvent.Phase = chromePhaseInstant
+			chromeEvent.Duration = 0
+			chromeEvent.Scope = "t"
+		}
+		if event.err != nil {
+			chromeEvent.Args = map[string]string{"error": event.err.Error()}
+		}
+		trace.TraceEvents = append(trace.TraceEvents, chromeEvent)
+	}
+
+	return trace
+}
+
+func microseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Microsecond)
+}
@@ -0,0 +1,84 @@
+package timeline
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/qdm12/goservices/hooks/internal/nested"
+)
+
+// CriticalPaths returns a plain text summary of the critical
+// path of each root start and stop event recorded, that is the
+// chain of nested events which determined its total duration.
+// For a sequence, this is every service started in order, and for
+// a group, this is the slowest service of the group.
+// For example:
+//
+//	critical path of start sequence s (1.5s):
+//	  start group g: 1s (66.7%)
+//	    start B: 1s (66.7%)
+//	  start C: 500ms (33.3%)
+func (r *Recorder) CriticalPaths() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var builder strings.Builder
+	for _, event := range r.events {
+		if event.parent != nil || !event.ended || event.phase == nested.PhaseCrash {
+			continue
+		}
+
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		fmt.Fprintf(&builder, "critical path of %s (%s):\n", event.name(), event.duration())
+		steps := criticalPath(event, 0)
+		for _, step := range steps[1:] {
+			percent := 100 * float64(step.event.duration()) / float64(event.duration()) //nolint:mnd
+			fmt.Fprintf(&builder, "%s%s: %s (%.1f%%)\n",
+				strings.Repeat("  ", step.depth), step.event.name(),
+				step.event.duration(), percent)
+		}
+	}
+	return builder.String()
+}
+
+type pathStep struct {
+	event *event
+	depth int
+}
+
+// criticalPath returns the critical path of the event given,
+// in chronological order and starting with the event itself.
+// It walks back from the event end, each time picking the child
+// event ending last before the current cursor, and recursing in it.
+func criticalPath(root *event, depth int) (steps []pathStep) {
+	var reversedChain [][]pathStep
+	used := make(map[*event]struct{}, len(root.children))
+	cursor := root.end
+	for {
+		var latest *event
+		for _, child := range root.children {
+			_, isUsed := used[child]
+			switch {
+			case isUsed, !child.ended, child.phase == nested.PhaseCrash,
+				child.end.After(cursor),
+				latest != nil && !child.end.After(latest.end):
+				continue
+			}
+			latest = child
+		}
+		if latest == nil {
+			break
+		}
+		used[latest] = struct{}{}
+		reversedChain = append(reversedChain, criticalPath(latest, depth+1))
+		cursor = latest.begin
+	}
+
+	steps = []pathStep{{event: root, depth: depth}}
+	for i := len(reversedChain) - 1; i >= 0; i-- {
+		steps = append(steps, reversedChain[i]...)
+	}
+	return steps
+}
@@ -0,0 +1,138 @@
+// Package timeline provides service handler hooks recording
+// the timeline of service starts and stops, which can be exported
+// in the Chrome trace event format and summarized as critical paths.
+package timeline
+
+import (
+	"sync"
+	"time"
+
+	"github.com/qdm12/goservices/hooks/internal/nested"
+)
+
+// Recorder records begin and end timestamps of each service
+// start and stop, together with their nesting in the service tree.
+//
+// The Recorder itself implements the service handler hooks,
+// recording events without parent. Hooks recording events
+// nested under a composite service event can be obtained with
+// the `Hooks` method, and a root service can be wrapped with
+// the `Wrap` method to record events for its own start and stop.
+type Recorder struct {
+	*rootHooks
+
+	timeNow func() time.Time
+	origin  time.Time
+
+	events []*event
+	// openEvents maps a phase and service name to
+	// the event begun and not yet ended.
+	openEvents map[nested.Key]*event
+	mutex      sync.Mutex
+}
+
+// rootHooks records events without parent.
+type rootHooks = nested.Hooks
+
+// New creates a new timeline recorder.
+func New() *Recorder {
+	recorder := &Recorder{
+		timeNow:    time.Now,
+		openEvents: make(map[nested.Key]*event),
+	}
+	recorder.rootHooks = nested.NewHooks((*phases)(recorder), "")
+	return recorder
+}
+
+type event struct {
+	phase    string
+	service  string
+	begin    time.Time
+	end      time.Time
+	ended    bool
+	err      error
+	parent   *event
+	children []*event
+}
+
+func (e *event) name() string {
+	return e.phase + " " + e.service
+}
+
+func (e *event) duration() time.Duration {
+	return e.end.Sub(e.begin)
+}
+
+// Hooks is the type of service handler hooks recording
+// events nested under the event of a parent service.
+type Hooks = nested.Hooks
+
+// Hooks returns service handler hooks recording events as
+// children of the event of the parent service for the same phase,
+// if the parent event is currently recorded. This should typically
+// be used as hooks for a composite service such as a sequence or
+// group, with `parent` set to the composite service string.
+func (r *Recorder) Hooks(parent string) *Hooks {
+	return nested.NewHooks((*phases)(r), parent)
+}
+
+// phases records events of the recorder for the hooks.
+type phases Recorder
+
+func (p *phases) Begin(phase, parent, service string) {
+	now := p.timeNow()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	event := p.newEvent(phase, parent, service, now)
+	p.openEvents[nested.Key{Phase: phase, Service: service}] = event
+}
+
+// newEvent creates and records a new event, as child of the parent
+// event for the same phase if it is open. The caller must hold the mutex.
+func (p *phases) newEvent(phase, parent, service string, begin time.Time) *event {
+	if p.origin.IsZero() {
+		p.origin = begin
+	}
+
+	newEvent := &event{
+		phase:   phase,
+		service: service,
+		begin:   begin,
+	}
+	p.events = append(p.events, newEvent)
+
+	parentEvent, ok := nested.Parent(p.openEvents, phase, parent)
+	if ok {
+		newEvent.parent = parentEvent
+		parentEvent.children = append(parentEvent.children, newEvent)
+	}
+	return newEvent
+}
+
+func (p *phases) End(phase, service string, err error) {
+	now := p.timeNow()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := nested.Key{Phase: phase, Service: service}
+	event, ok := p.openEvents[key]
+	if !ok {
+		return
+	}
+	delete(p.openEvents, key)
+	event.end = now
+	event.ended = true
+	event.err = err
+}
+
+func (p *phases) Crash(parent, service string, err error) {
+	now := p.timeNow()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	event := p.newEvent(nested.PhaseCrash, parent, service, now)
+	event.end = now
+	event.ended = true
+	event.err = err
+}
@@ -0,0 +1,97 @@
+package timeline
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRecorder returns a recorder populated with the events of
+// a sequence `s` starting a group `g` of services A and B in parallel
+// and then a service C, crashing and being stopped.
+func newTestRecorder() *Recorder {
+	recorder := New()
+	now := time.Unix(0, 0)
+	recorder.timeNow = func() time.Time { return now }
+	advance := func(d time.Duration) { now = now.Add(d) }
+
+	sequenceHooks := recorder.Hooks("sequence s")
+	groupHooks := recorder.Hooks("group g")
+
+	recorder.OnStart("sequence s")
+	sequenceHooks.OnStart("group g")
+	groupHooks.OnStart("A")
+	groupHooks.OnStart("B")
+	advance(500 * time.Millisecond)
+	groupHooks.OnStarted("B", nil)
+	advance(500 * time.Millisecond)
+	groupHooks.OnStarted("A", nil)
+	sequenceHooks.OnStarted("group g", nil)
+	sequenceHooks.OnStart("C")
+	advance(500 * time.Millisecond)
+	sequenceHooks.OnStarted("C", nil)
+	recorder.OnStarted("sequence s", nil)
+
+	advance(time.Second)
+	recorder.OnCrash("C", errors.New("test error"))
+	recorder.OnStop("sequence s")
+	sequenceHooks.OnStop("group g")
+	groupHooks.OnStop("A")
+	advance(100 * time.Millisecond)
+	groupHooks.OnStopped("A", nil)
+	sequenceHooks.OnStopped("group g", nil)
+	recorder.OnStopped("sequence s", nil)
+
+	return recorder
+}
+
+func Test_Recorder_CriticalPaths(t *testing.T) {
+	t.Parallel()
+
+	recorder := newTestRecorder()
+
+	summary := recorder.CriticalPaths()
+
+	const expected = `critical path of start sequence s (1.5s):
+  start group g: 1s (66.7%)
+    start A: 1s (66.7%)
+  start C: 500ms (33.3%)
+
+critical path of stop sequence s (100ms):
+  stop group g: 100ms (100.0%)
+    stop A: 100ms (100.0%)
+`
+	assert.Equal(t, expected, summary)
+}
+
+func Test_Recorder_WriteChromeTrace(t *testing.T) {
+	t.Parallel()
+
+	recorder := newTestRecorder()
+	buffer := bytes.NewBuffer(nil)
+
+	err := recorder.WriteChromeTrace(buffer)
+
+	require.NoError(t, err)
+	const expected = `{"traceEvents":[` +
+		`{"name":"thread_name","ph":"M","ts":0,"pid":1,"tid":1,"args":{"name":"sequence s"}},` +
+		`{"name":"start sequence s","cat":"start","ph":"X","ts":0,"dur":1500000,"pid":1,"tid":1},` +
+		`{"name":"thread_name","ph":"M","ts":0,"pid":1,"tid":2,"args":{"name":"group g"}},` +
+		`{"name":"start group g","cat":"start","ph":"X","ts":0,"dur":1000000,"pid":1,"tid":2},` +
+		`{"name":"thread_name","ph":"M","ts":0,"pid":1,"tid":3,"args":{"name":"A"}},` +
+		`{"name":"start A","cat":"start","ph":"X","ts":0,"dur":1000000,"pid":1,"tid":3},` +
+		`{"name":"thread_name","ph":"M","ts":0,"pid":1,"tid":4,"args":{"name":"B"}},` +
+		`{"name":"start B","cat":"start","ph":"X","ts":0,"dur":500000,"pid":1,"tid":4},` +
+		`{"name":"thread_name","ph":"M","ts":0,"pid":1,"tid":5,"args":{"name":"C"}},` +
+		`{"name":"start C","cat":"start","ph":"X","ts":1000000,"dur":500000,"pid":1,"tid":5},` +
+		`{"name":"crash C","cat":"crash","ph":"i","ts":2500000,"pid":1,"tid":5,"s":"t","args":{"error":"test error"}},` +
+		`{"name":"stop sequence s","cat":"stop","ph":"X","ts":2500000,"dur":100000,"pid":1,"tid":1},` +
+		`{"name":"stop group g","cat":"stop","ph":"X","ts":2500000,"dur":100000,"pid":1,"tid":2},` +
+		`{"name":"stop A","cat":"stop","ph":"X","ts":2500000,"dur":100000,"pid":1,"tid":3}` +
+		`],"displayTimeUnit":"ms"}` + "\n"
+	assert.Equal(t, expected, buffer.String())
+}
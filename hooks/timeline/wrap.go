@@ -0,0 +1,19 @@
+package timeline
+
+import (
+	"github.com/qdm12/goservices"
+	"github.com/qdm12/goservices/hooks/internal/nested"
+)
+
+// Service is the type of service wrapping a service
+// to record events for its start and stop calls.
+type Service = nested.Service
+
+// Wrap returns a service wrapping the given service to record
+// events for its own start and stop calls. This is typically used
+// on the root service of the tree, for its start and stop events to
+// be the parent events of events recorded by hooks obtained with
+// `recorder.Hooks(service.String())`.
+func (r *Recorder) Wrap(service goservices.Service) *Service {
+	return nested.Wrap(service, r)
+}
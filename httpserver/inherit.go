@@ -0,0 +1,109 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrListenerNoDeadline       = errors.New("listener does not support deadlines")
+	ErrInheritedListenersClosed = errors.New("inherited listeners are closed")
+)
+
+// getInheritedListeners returns the listeners to serve on from the
+// `Listeners` and `FileDescriptors` settings. File descriptors are
+// converted to listeners only once. If `KeepInheritedListeners` is set,
+// listeners are wrapped to be kept open on stop and are cached to be
+// served again, otherwise an error is returned once they were served.
+func (s *Server) getInheritedListeners() (listeners []net.Listener, err error) {
+	if s.inheritedServed {
+		return nil, fmt.Errorf("%w: they are closed when the server stops "+
+			"unless KeepInheritedListeners is set", ErrInheritedListenersClosed)
+	}
+
+	if s.inheritedListeners != nil {
+		for _, listener := range s.inheritedListeners {
+			kept, ok := listener.(*keptListener)
+			if ok {
+				kept.reopen()
+			}
+		}
+		return s.inheritedListeners, nil
+	}
+
+	listeners = make([]net.Listener, 0, len(s.settings.Listeners)+len(s.settings.FileDescriptors))
+	listeners = append(listeners, s.settings.Listeners...)
+	for _, fd := range s.settings.FileDescriptors {
+		file := os.NewFile(fd, fmt.Sprintf("fd %d", fd))
+		listener, err := net.FileListener(file)
+		_ = file.Close() // the listener uses a duplicate file descriptor
+		if err != nil {
+			for _, listener := range listeners[len(s.settings.Listeners):] {
+				_ = listener.Close()
+			}
+			return nil, fmt.Errorf("creating listener from file descriptor %d: %w", fd, err)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	if !s.settings.KeepInheritedListeners {
+		// Listeners are closed when the server stops.
+		s.inheritedServed = len(listeners) > 0
+		return listeners, nil
+	}
+
+	for i, listener := range listeners {
+		listeners[i], err = newKeptListener(listener)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.inheritedListeners = listeners
+	return listeners, nil
+}
+
+// keptListener is a listener which is not closed when its
+// Close method is called, but instead makes its Accept method
+// return `net.ErrClosed`, until it is reopened.
+type keptListener struct {
+	net.Listener
+	deadliner interface{ SetDeadline(t time.Time) error }
+	closed    atomic.Bool
+}
+
+func newKeptListener(listener net.Listener) (kept *keptListener, err error) {
+	deadliner, ok := listener.(interface{ SetDeadline(t time.Time) error })
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrListenerNoDeadline, listener)
+	}
+	return &keptListener{
+		Listener:  listener,
+		deadliner: deadliner,
+	}, nil
+}
+
+func (k *keptListener) Accept() (connection net.Conn, err error) {
+	connection, err = k.Listener.Accept()
+	if err != nil && k.closed.Load() {
+		return nil, net.ErrClosed
+	}
+	return connection, err
+}
+
+// Close unblocks the Accept method without closing the listener.
+func (k *keptListener) Close() (err error) {
+	if k.closed.Swap(true) {
+		return nil
+	}
+	// Set a deadline in the past to unblock Accept calls.
+	return k.deadliner.SetDeadline(time.Unix(1, 0))
+}
+
+func (k *keptListener) reopen() {
+	k.closed.Store(false)
+	_ = k.deadliner.SetDeadline(time.Time{})
+}
@@ -0,0 +1,187 @@
+//go:build unix
+
+package httpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Server_inheritedListeners(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		keep            bool
+		fileDescriptors bool
+	}{
+		"listener_closed":        {},
+		"listener_kept":          {keep: true},
+		"file_descriptor_closed": {fileDescriptors: true},
+		"file_descriptor_kept":   {fileDescriptors: true, keep: true},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = listener.Close() })
+			address := listener.Addr().String()
+
+			settings := Settings{
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				}),
+				KeepInheritedListeners: testCase.keep,
+			}
+			if testCase.fileDescriptors {
+				// The server owns the file descriptor given.
+				file, err := listener.(*net.TCPListener).File()
+				require.NoError(t, err)
+				fd, err := syscall.Dup(int(file.Fd()))
+				require.NoError(t, err)
+				_ = file.Close()
+				_ = listener.Close()
+				settings.FileDescriptors = []uintptr{uintptr(fd)}
+			} else {
+				settings.Listeners = []net.Listener{listener}
+			}
+
+			server, err := New(settings)
+			require.NoError(t, err)
+
+			_, err = server.Start(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, []string{address}, server.GetAddresses())
+			assertTeapot(t, address)
+			err = server.Stop()
+			require.NoError(t, err)
+
+			if !testCase.keep {
+				_, err = net.DialTimeout("tcp", address, time.Second)
+				assert.Error(t, err)
+				_, err = server.Start(context.Background())
+				assert.ErrorIs(t, err, ErrInheritedListenersClosed)
+				return
+			}
+
+			// The kept listener is served again on restart.
+			_, err = server.Start(context.Background())
+			require.NoError(t, err)
+			assertTeapot(t, address)
+			err = server.Stop()
+			require.NoError(t, err)
+
+			// The kept listener still accepts connections.
+			connection, err := net.DialTimeout("tcp", address, time.Second)
+			require.NoError(t, err)
+			_ = connection.Close()
+		})
+	}
+}
+
+func assertTeapot(t *testing.T, address string) {
+	t.Helper()
+	client := &http.Client{Timeout: time.Second}
+	request, err := http.NewRequestWithContext(context.Background(),
+		http.MethodGet, "http://"+address, nil)
+	require.NoError(t, err)
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusTeapot, response.StatusCode)
+}
+
+func Test_keptListener(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	kept, err := newKeptListener(listener)
+	require.NoError(t, err)
+
+	acceptErr := make(chan error)
+	go func() {
+		_, err := kept.Accept()
+		acceptErr <- err
+	}()
+
+	err = kept.Close()
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-acceptErr, net.ErrClosed)
+
+	kept.reopen()
+	go func() {
+		connection, err := kept.Accept()
+		if err == nil {
+			_ = connection.Close()
+		}
+		acceptErr <- err
+	}()
+	connection, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_ = connection.Close()
+	assert.NoError(t, <-acceptErr)
+}
+
+//nolint:paralleltest // environment variables are process wide
+func Test_ListenersFromSystemd(t *testing.T) {
+	testCases := map[string]struct {
+		pid        string
+		fds        string
+		errWrapped error
+		errMessage string
+	}{
+		"not_activated": {
+			fds: "1",
+		},
+		"other_process": {
+			pid: "1",
+			fds: "1",
+		},
+		"no_file_descriptor": {
+			pid: strconv.Itoa(os.Getpid()),
+			fds: "0",
+		},
+		"invalid_file_descriptors_count": {
+			pid:        strconv.Itoa(os.Getpid()),
+			fds:        "x",
+			errWrapped: ErrListenFDsNotValid,
+			errMessage: "LISTEN_FDS value is not valid: x",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", testCase.pid)
+			t.Setenv("LISTEN_FDS", testCase.fds)
+			t.Setenv("LISTEN_FDNAMES", "http")
+
+			listeners, err := ListenersFromSystemd(true)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				assert.Nil(t, listeners)
+			} else {
+				assert.Empty(t, listeners)
+			}
+			for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+				_, set := os.LookupEnv(key)
+				assert.False(t, set, key)
+			}
+		})
+	}
+}
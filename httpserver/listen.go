@@ -0,0 +1,122 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+const unixAddressPrefix = "unix:"
+
+var (
+	ErrUnixSocketInUse    = errors.New("unix socket is in use")
+	ErrUnixSocketNotValid = errors.New("file exists and is not a unix socket")
+)
+
+// listen listens on the address given, which is a unix socket
+// path if it is prefixed with `unix:`, and a TCP address otherwise.
+func (s *Server) listen(ctx context.Context, address string) (listener net.Listener, err error) {
+	listenConfig := net.ListenConfig{}
+	path, isUnix := strings.CutPrefix(address, unixAddressPrefix)
+	if !isUnix {
+		return listenConfig.Listen(ctx, "tcp", address)
+	}
+
+	err = removeStaleSocket(path)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err = listenConfig.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.setSocketPermissions(path)
+	if err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	return listener, nil
+}
+
+// removeStaleSocket removes the unix socket at the path given if
+// no process is listening on it anymore, for example after a crash.
+func removeStaleSocket(path string) (err error) {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("checking for stale unix socket: %w", err)
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("%w: %s", ErrUnixSocketNotValid, path)
+	}
+
+	connection, err := net.Dial("unix", path)
+	if err == nil {
+		_ = connection.Close()
+		return fmt.Errorf("%w: %s", ErrUnixSocketInUse, path)
+	} else if !errors.Is(err, syscall.ECONNREFUSED) {
+		return fmt.Errorf("checking for stale unix socket: %w", err)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("removing stale unix socket: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) setSocketPermissions(path string) (err error) {
+	if s.settings.UnixSocketMode != nil {
+		err = os.Chmod(path, *s.settings.UnixSocketMode)
+		if err != nil {
+			return fmt.Errorf("setting unix socket mode: %w", err)
+		}
+	}
+
+	if s.settings.UnixSocketUID != nil || s.settings.UnixSocketGID != nil {
+		uid, gid := -1, -1
+		if s.settings.UnixSocketUID != nil {
+			uid = *s.settings.UnixSocketUID
+		}
+		if s.settings.UnixSocketGID != nil {
+			gid = *s.settings.UnixSocketGID
+		}
+		err = os.Lchown(path, uid, gid)
+		if err != nil {
+			return fmt.Errorf("setting unix socket owner: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeUnixSockets removes the unix socket files of the server,
+// in case closing their listener did not already remove them.
+func (s *Server) removeUnixSockets() {
+	for _, address := range s.settings.listeningAddresses() {
+		path, isUnix := strings.CutPrefix(address, unixAddressPrefix)
+		if isUnix {
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// listenerAddress returns the address of the listener given, in
+// the format of the listening address settings.
+func listenerAddress(listener net.Listener) string {
+	address := listener.Addr()
+	if address.Network() == "unix" {
+		return unixAddressPrefix + address.String()
+	}
+	return address.String()
+}
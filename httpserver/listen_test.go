@@ -0,0 +1,101 @@
+package httpserver
+
+import (
+	"context"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Server_multipleAddresses(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "server.sock")
+
+	// Create a stale socket, as left by a crashed process.
+	staleListener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, staleListener.Close())
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	mode := os.FileMode(0o600)
+	server, err := New(Settings{
+		Handler:        handler,
+		Addresses:      []string{"127.0.0.1:0", "unix:" + socketPath},
+		UnixSocketMode: &mode,
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+
+	addresses := server.GetAddresses()
+	require.Len(t, addresses, 2)
+	assert.True(t, strings.HasPrefix(addresses[0], "127.0.0.1:"))
+	assert.Equal(t, "unix:"+socketPath, addresses[1])
+	assert.Equal(t, addresses[0], server.GetAddress())
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, mode, info.Mode().Perm())
+
+	// A second server cannot use the socket in use.
+	otherServer, err := New(Settings{
+		Handler:   handler,
+		Addresses: []string{"unix:" + socketPath},
+	})
+	require.NoError(t, err)
+	_, err = otherServer.Start(context.Background())
+	assert.ErrorIs(t, err, ErrUnixSocketInUse)
+
+	tcpResponse, err := http.Get("http://" + addresses[0]) //nolint:noctx
+	require.NoError(t, err)
+	_ = tcpResponse.Body.Close()
+	assert.Equal(t, http.StatusTeapot, tcpResponse.StatusCode)
+
+	unixClient := &http.Client{
+		Timeout: time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+	unixResponse, err := unixClient.Get("http://unix") //nolint:noctx
+	require.NoError(t, err)
+	_ = unixResponse.Body.Close()
+	assert.Equal(t, http.StatusTeapot, unixResponse.StatusCode)
+	unixClient.CloseIdleConnections()
+
+	err = server.Stop()
+	require.NoError(t, err)
+
+	_, err = os.Stat(socketPath)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func Test_removeStaleSocket(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	err := removeStaleSocket(filepath.Join(directory, "missing.sock"))
+	assert.NoError(t, err)
+
+	regularFile := filepath.Join(directory, "regular")
+	require.NoError(t, os.WriteFile(regularFile, nil, 0o600))
+	err = removeStaleSocket(regularFile)
+	assert.ErrorIs(t, err, ErrUnixSocketNotValid)
+}
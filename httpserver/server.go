@@ -3,27 +3,42 @@ package httpserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/qdm12/goservices"
 )
 
+// ErrShutdownTimeout is wrapped by the error returned by `Stop`
+// when connections are forcibly closed after the shutdown timeout.
+var ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+
 // Server is an HTTP server implementation.
 type Server struct {
 	// Dependencies injected
 	settings Settings
 
 	// Internal fields
-	server                http.Server
+	server                *http.Server
 	startStopMutex        sync.Mutex
 	state                 goservices.State
 	stateMutex            sync.RWMutex
-	listeningAddress      string
+	listeningAddresses    []string
+	inheritedListeners    []net.Listener
+	inheritedServed       bool
 	listeningAddressMutex sync.RWMutex
+	redirectServer        *http.Server
+	redirectAddress       string
+	baseCancel            context.CancelFunc
+	connections           map[net.Conn]http.ConnState
+	connectionsMutex      sync.Mutex
 }
 
 // New creates a new HTTP server with a name, listening on
@@ -48,11 +63,60 @@ func (s *Server) String() string {
 	return *s.settings.Name + " http server"
 }
 
-// GetAddress obtains the address the HTTP server is listening on.
+// GetAddress obtains the first address the HTTP server is
+// listening on, see `GetAddresses` to obtain all of them.
 func (s *Server) GetAddress() (address string) {
 	s.listeningAddressMutex.RLock()
 	defer s.listeningAddressMutex.RUnlock()
-	return s.listeningAddress
+	if len(s.listeningAddresses) == 0 {
+		return ""
+	}
+	return s.listeningAddresses[0]
+}
+
+// GetAddresses obtains all the addresses the HTTP server is
+// listening on, in the order of the listening addresses settings.
+// Unix socket addresses are prefixed with `unix:`.
+func (s *Server) GetAddresses() (addresses []string) {
+	s.listeningAddressMutex.RLock()
+	defer s.listeningAddressMutex.RUnlock()
+	return slices.Clone(s.listeningAddresses)
+}
+
+// Connections returns the number of active and idle connections
+// of the server. A connection is active from when it is accepted
+// until it becomes idle between requests, and hijacked connections,
+// for example upgraded to websockets, are no longer counted.
+func (s *Server) Connections() (active, idle int) {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+	for _, state := range s.connections {
+		if state == http.StateIdle {
+			idle++
+		} else {
+			active++
+		}
+	}
+	return active, idle
+}
+
+func (s *Server) trackConnection(connection net.Conn, state http.ConnState) {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+	switch state {
+	case http.StateNew, http.StateActive, http.StateIdle:
+		s.connections[connection] = state
+	case http.StateHijacked, http.StateClosed:
+		delete(s.connections, connection)
+	}
+}
+
+// GetRedirectAddress obtains the address the companion HTTP to
+// HTTPS redirect server is listening on, if it is enabled.
+func (s *Server) GetRedirectAddress() (address string) {
+	s.listeningAddressMutex.RLock()
+	defer s.listeningAddressMutex.RUnlock()
+	return s.redirectAddress
 }
 
 // Start starts the HTTP server service.
@@ -75,6 +139,14 @@ func (s *Server) Start(ctx context.Context) (runError <-chan error, err error) {
 
 	s.state = goservices.StateStarting
 
+	var tlsConfig *tls.Config
+	if s.settings.TLS != nil {
+		tlsConfig, err = newTLSConfig(*s.settings.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("configuring TLS: %w", err)
+		}
+	}
+
 	// The listener below will either be stopped by:
 	// - this Start function context being done before the server
 	//   starts listening
@@ -94,22 +166,93 @@ func (s *Server) Start(ctx context.Context) (runError <-chan error, err error) {
 		}
 	}()
 
-	listenConfig := net.ListenConfig{}
-	listener, err := listenConfig.Listen(listenCtx, "tcp", *s.settings.Address) //nolint:contextcheck
+	addresses := s.settings.listeningAddresses()
+	listeners := make([]net.Listener, 0, len(addresses))
+	closeListeners := func() {
+		for _, listener := range listeners {
+			_ = listener.Close()
+		}
+	}
+	for _, address := range addresses {
+		listener, err := s.listen(listenCtx, address) //nolint:contextcheck
+		if err != nil {
+			closeListeners()
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	var redirectListener net.Listener
+	if s.settings.TLS != nil && *s.settings.TLS.RedirectAddress != "" {
+		listenConfig := net.ListenConfig{}
+		redirectListener, err = listenConfig.Listen(listenCtx, "tcp", //nolint:contextcheck
+			*s.settings.TLS.RedirectAddress)
+		if err != nil {
+			closeListeners()
+			return nil, fmt.Errorf("listening for HTTPS redirect: %w", err)
+		}
+	}
+
+	// Inherited listeners are served instead of listening, and are
+	// not closed if Start fails so it can be called again.
+	inheritedListeners, err := s.getInheritedListeners()
 	if err != nil {
-		return nil, err
+		closeListeners()
+		if redirectListener != nil {
+			_ = redirectListener.Close()
+		}
+		s.state = goservices.StateStopped
+		return nil, fmt.Errorf("getting inherited listeners: %w", err)
 	}
+	listeners = append(listeners, inheritedListeners...)
 
 	s.listeningAddressMutex.Lock()
 	defer s.listeningAddressMutex.Unlock()
-	s.listeningAddress = listener.Addr().String()
-	s.server = http.Server{
-		Addr:              s.listeningAddress,
+	s.listeningAddresses = make([]string, len(listeners))
+	for i, listener := range listeners {
+		s.listeningAddresses[i] = listenerAddress(listener)
+	}
+
+	// The base context of requests is canceled when the
+	// server starts shutting down, so handlers can abort
+	// long operations using the request context.
+	baseCtx, baseCancel := context.WithCancel(context.Background())
+	s.baseCancel = baseCancel
+	s.connectionsMutex.Lock()
+	s.connections = make(map[net.Conn]http.ConnState)
+	s.connectionsMutex.Unlock()
+	s.server = &http.Server{
+		Addr:              s.listeningAddresses[0],
 		Handler:           s.settings.Handler,
 		ReadHeaderTimeout: s.settings.ReadHeaderTimeout,
 		ReadTimeout:       s.settings.ReadTimeout,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+		ConnState:         s.trackConnection,
+		TLSConfig:         tlsConfig,
+	}
+	s.settings.Logger.Info(fmt.Sprintf("%s listening on %s", s,
+		strings.Join(s.listeningAddresses, ", ")))
+
+	s.redirectServer = nil
+	s.redirectAddress = ""
+	if redirectListener != nil {
+		httpsPort := "443"
+		for _, listener := range listeners {
+			tcpAddress, ok := listener.Addr().(*net.TCPAddr)
+			if ok {
+				httpsPort = strconv.Itoa(tcpAddress.Port)
+				break
+			}
+		}
+		s.redirectAddress = redirectListener.Addr().String()
+		s.redirectServer = &http.Server{
+			Handler:           newRedirectHandler(httpsPort),
+			ReadHeaderTimeout: s.settings.ReadHeaderTimeout,
+			ReadTimeout:       s.settings.ReadTimeout,
+		}
+		s.settings.Logger.Info(fmt.Sprintf("%s redirecting HTTP on %s to HTTPS",
+			s, s.redirectAddress))
 	}
-	s.settings.Logger.Info(fmt.Sprintf("%s listening on %s", s, s.listeningAddress))
 
 	runErrorBiDirectional := make(chan error)
 	runError = runErrorBiDirectional
@@ -119,19 +262,49 @@ func (s *Server) Start(ctx context.Context) (runError <-chan error, err error) {
 	// function returns an error instantly.
 	s.stateMutex.Lock()
 
-	go func() {
-		close(ready)
-		err = s.server.Serve(listener)
-		if errors.Is(err, http.ErrServerClosed) {
+	crash := func(err error) {
+		s.stateMutex.Lock()
+		if s.state == goservices.StateCrashed {
+			// the other server already crashed
+			s.stateMutex.Unlock()
 			return
 		}
-		s.stateMutex.Lock()
 		s.state = goservices.StateCrashed
 		s.stateMutex.Unlock()
 		runErrorBiDirectional <- err
-	}()
+	}
+
+	// Any listener failing crashes the server.
+	for _, listener := range listeners {
+		go func() {
+			ready <- struct{}{}
+			var err error
+			if tlsConfig == nil {
+				err = s.server.Serve(listener)
+			} else {
+				err = s.server.ServeTLS(listener, "", "")
+			}
+			if errors.Is(err, http.ErrServerClosed) {
+				return
+			}
+			crash(err)
+		}()
+		<-ready
+	}
+
+	if s.redirectServer != nil {
+		redirectReady := make(chan struct{})
+		go func(server *http.Server) {
+			close(redirectReady)
+			err := server.Serve(redirectListener)
+			if errors.Is(err, http.ErrServerClosed) {
+				return
+			}
+			crash(fmt.Errorf("HTTPS redirect server: %w", err))
+		}(s.redirectServer)
+		<-redirectReady
+	}
 
-	<-ready
 	close(forgetStartCtx)
 	<-startCtxWaitDone
 	s.state = goservices.StateRunning
@@ -141,6 +314,11 @@ func (s *Server) Start(ctx context.Context) (runError <-chan error, err error) {
 }
 
 // Stop stops the HTTP server service.
+// The base context of requests is canceled first, and the server
+// then waits for connections to finish, for at most the shutdown
+// timeout. Connections still open at the end of the timeout are
+// forcibly closed, and an error wrapping `ErrShutdownTimeout` with
+// the number of connections closed is returned.
 func (s *Server) Stop() (err error) {
 	s.startStopMutex.Lock()
 	defer s.startStopMutex.Unlock()
@@ -149,7 +327,15 @@ func (s *Server) Stop() (err error) {
 	switch s.state {
 	case goservices.StateRunning: // continue stopping the server
 	case goservices.StateCrashed: // server is already stopped
+		s.state = goservices.StateStopped
 		s.stateMutex.Unlock()
+		// Close the eventual other server still serving.
+		_ = s.server.Close()
+		if s.redirectServer != nil {
+			_ = s.redirectServer.Close()
+		}
+		s.baseCancel()
+		s.removeUnixSockets()
 		return nil
 	case goservices.StateStopped:
 		s.stateMutex.Unlock()
@@ -161,11 +347,39 @@ func (s *Server) Stop() (err error) {
 	s.state = goservices.StateStopping
 	s.stateMutex.Unlock()
 
-	s.settings.CancelHandler()
+	s.baseCancel()
 	shutdownCtx, cancel := context.WithTimeout(
 		context.Background(), s.settings.ShutdownTimeout)
 	defer cancel()
+
+	var redirectErr error
+	if s.redirectServer != nil {
+		redirectErr = s.redirectServer.Shutdown(shutdownCtx)
+		if redirectErr != nil {
+			_ = s.redirectServer.Close()
+		}
+	}
+
 	err = s.server.Shutdown(shutdownCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Forcibly close connections still open after the shutdown timeout.
+		active, idle := s.Connections()
+		closeErr := s.server.Close()
+		err = fmt.Errorf("%w after %s: %d connections forcibly closed",
+			ErrShutdownTimeout, s.settings.ShutdownTimeout, active+idle)
+		if closeErr != nil {
+			err = fmt.Errorf("%w (and closing: %w)", err, closeErr)
+		}
+	}
+	if redirectErr != nil {
+		redirectErr = fmt.Errorf("shutting down HTTPS redirect server: %w", redirectErr)
+		if err == nil {
+			err = redirectErr
+		} else {
+			err = fmt.Errorf("%w (and %w)", err, redirectErr)
+		}
+	}
+	s.removeUnixSockets()
 	s.state = goservices.StateStopped
 	return err
 }
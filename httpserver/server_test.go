@@ -4,7 +4,6 @@ import (
 	"context"
 	"net"
 	"net/http"
-	"reflect"
 	"regexp"
 	"testing"
 	"time"
@@ -18,8 +17,6 @@ import (
 func Test_New(t *testing.T) {
 	t.Parallel()
 
-	cancelHandler := func() {}
-
 	testCases := map[string]struct {
 		settings       Settings
 		expectedServer *Server
@@ -30,8 +27,7 @@ func Test_New(t *testing.T) {
 		},
 		"valid settings": {
 			settings: Settings{
-				Handler:       http.NewServeMux(),
-				CancelHandler: cancelHandler,
+				Handler: http.NewServeMux(),
 			},
 			expectedServer: &Server{
 				state: goservices.StateStopped,
@@ -43,7 +39,6 @@ func Test_New(t *testing.T) {
 					ReadTimeout:       10 * time.Second,
 					ReadHeaderTimeout: time.Second,
 					Logger:            &noopLogger{},
-					CancelHandler:     cancelHandler,
 				},
 			},
 		},
@@ -57,12 +52,6 @@ func Test_New(t *testing.T) {
 
 			if testCase.errMessage == "" {
 				assert.NoError(t, err)
-				assert.Equal(t,
-					reflect.ValueOf(testCase.expectedServer.settings.CancelHandler).Pointer(),
-					reflect.ValueOf(server.settings.CancelHandler).Pointer())
-				// Remove function pointer before comparison
-				testCase.expectedServer.settings.CancelHandler = nil
-				server.settings.CancelHandler = nil
 			} else {
 				assert.EqualError(t, err, testCase.errMessage)
 			}
@@ -90,12 +79,14 @@ func Test_Server_GetAddress(t *testing.T) {
 	t.Parallel()
 
 	server := &Server{
-		listeningAddress: "x",
+		listeningAddresses: []string{"x", "y"},
 	}
 
 	address := server.GetAddress()
+	addresses := server.GetAddresses()
 
 	assert.Equal(t, "x", address)
+	assert.Equal(t, []string{"x", "y"}, addresses)
 }
 
 func Test_Server_success(t *testing.T) {
@@ -162,3 +153,74 @@ func Test_Server_startError(t *testing.T) {
 	require.EqualError(t, err, "listen tcp: address -1: invalid port")
 	assert.Nil(t, runtimeError)
 }
+
+func Test_Server_shutdown(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		handlerStuck bool
+		errWrapped   error
+		errMessage   string
+	}{
+		"handler_canceled": {},
+		"handler_stuck": {
+			handlerStuck: true,
+			errWrapped:   ErrShutdownTimeout,
+			errMessage:   "shutdown timeout exceeded after 50ms: 1 connections forcibly closed",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			unblock := make(chan struct{})
+			defer close(unblock)
+			handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				if testCase.handlerStuck {
+					<-unblock
+					return
+				}
+				// Wait for the base context to be canceled at shutdown.
+				<-r.Context().Done()
+			})
+
+			server, err := New(Settings{
+				Handler:         handler,
+				Address:         stringPtr("127.0.0.1:0"),
+				ShutdownTimeout: 50 * time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			_, err = server.Start(context.Background())
+			require.NoError(t, err)
+
+			requestDone := make(chan struct{})
+			go func() {
+				defer close(requestDone)
+				response, err := http.Get("http://" + server.GetAddress()) //nolint:noctx
+				if err == nil {
+					_ = response.Body.Close()
+				}
+			}()
+
+			require.Eventually(t, func() bool {
+				active, _ := server.Connections()
+				return active == 1
+			}, time.Second, time.Millisecond)
+
+			err = server.Stop()
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			<-requestDone
+
+			if !testCase.handlerStuck {
+				active, idle := server.Connections()
+				assert.Zero(t, active)
+				assert.Zero(t, idle)
+			}
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package httpserver
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -21,19 +22,52 @@ type Settings struct {
 	Name *string
 	// Address is the listening address to use.
 	// It defaults to the empty string (random port) if
-	// left unset.
-	Address           *string
+	// left unset and no other listening address, listener
+	// or file descriptor is set.
+	Address *string
+	// Addresses are listening addresses to use, to listen on
+	// multiple addresses at once. An address prefixed with `unix:`
+	// is a unix domain socket path, for example `unix:/run/app.sock`,
+	// and other addresses are TCP addresses. It cannot be set
+	// together with `Address`. It is empty by default.
+	Addresses []string
+	// Listeners are existing listeners to serve on, in addition to
+	// the listening addresses, for example listeners obtained with
+	// `ListenersFromSystemd` for systemd socket activation.
+	// It is empty by default.
+	Listeners []net.Listener
+	// FileDescriptors are file descriptors of listening sockets to
+	// serve on, in addition to the listening addresses, for example
+	// inherited from a parent process. They are converted to listeners
+	// once, on the first `Start` call. It is empty by default.
+	FileDescriptors []uintptr
+	// KeepInheritedListeners, if true, keeps the `Listeners` and
+	// `FileDescriptors` listeners open when the server stops, so
+	// they can be served again when restarting the server, or handed
+	// over to another process. It defaults to false, meaning these
+	// listeners are closed when the server stops.
+	KeepInheritedListeners bool
+	// UnixSocketMode is the file mode to set on unix sockets.
+	// It defaults to nil, meaning the mode is left as created.
+	UnixSocketMode *os.FileMode
+	// UnixSocketUID and UnixSocketGID are the user and group
+	// ids to set as owner of unix sockets. They default to nil,
+	// meaning the owner is left unchanged.
+	UnixSocketUID     *int
+	UnixSocketGID     *int
 	ReadTimeout       time.Duration
 	ReadHeaderTimeout time.Duration
-	ShutdownTimeout   time.Duration
+	// ShutdownTimeout is the maximum duration to wait for
+	// connections to finish when stopping the server. Once it
+	// expires, remaining connections are forcibly closed.
+	ShutdownTimeout time.Duration
 	// Logger is the logger to use to log when the server
 	// is starting and on what address it is listening.
 	// It defaults to a no-op logger.
 	Logger Infoer
-	// CancelHandler is a cancel function to be called
-	// when the server is shutting down. By default it is a no-op.
-	// It is notably useful to cancel operations in the handler.
-	CancelHandler context.CancelFunc
+	// TLS, if set, makes the server serve HTTPS using these settings.
+	// It defaults to nil, meaning the server serves plain HTTP.
+	TLS *TLSSettings
 }
 
 // SetDefaults sets the default values for the settings.
@@ -42,7 +76,8 @@ func (s *Settings) SetDefaults() {
 		s.Name = new(string)
 	}
 
-	if s.Address == nil {
+	if s.Address == nil && len(s.Addresses) == 0 &&
+		len(s.Listeners) == 0 && len(s.FileDescriptors) == 0 {
 		s.Address = new(string)
 	}
 
@@ -65,13 +100,19 @@ func (s *Settings) SetDefaults() {
 		s.Logger = new(noopLogger)
 	}
 
-	if s.CancelHandler == nil {
-		s.CancelHandler = func() {}
+	if s.TLS != nil {
+		tlsSettings := *s.TLS
+		tlsSettings.SetDefaults()
+		s.TLS = &tlsSettings
 	}
 }
 
 var (
-	ErrHandlerIsNil = errors.New("handler is nil")
+	ErrHandlerIsNil             = errors.New("handler is nil")
+	ErrAddressAndAddressesSet   = errors.New("address and addresses cannot be both set")
+	ErrUnixSocketPathEmpty      = errors.New("unix socket path is empty")
+	ErrListeningAddressNotValid = errors.New("listening address is not valid")
+	ErrListenerIsNil            = errors.New("listener is nil")
 )
 
 // Validate validates the settings and returns an error
@@ -81,12 +122,48 @@ func (s Settings) Validate() (err error) {
 		return fmt.Errorf("%w", ErrHandlerIsNil)
 	}
 
-	if *s.Address != "" {
-		_, err = net.ResolveTCPAddr("tcp", *s.Address)
+	if s.Address != nil && len(s.Addresses) > 0 {
+		return fmt.Errorf("%w", ErrAddressAndAddressesSet)
+	}
+
+	for i, listener := range s.Listeners {
+		if listener == nil {
+			return fmt.Errorf("%w: at index %d", ErrListenerIsNil, i)
+		}
+	}
+
+	for _, address := range s.listeningAddresses() {
+		path, isUnix := strings.CutPrefix(address, unixAddressPrefix)
+		switch {
+		case isUnix && path == "":
+			return fmt.Errorf("%w: %s", ErrUnixSocketPathEmpty, address)
+		case isUnix, address == "":
+		default:
+			_, err = net.ResolveTCPAddr("tcp", address)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrListeningAddressNotValid, err)
+			}
+		}
+	}
+
+	if s.TLS != nil {
+		err = s.TLS.Validate()
 		if err != nil {
-			return fmt.Errorf("listening address is not valid: %w", err)
+			return fmt.Errorf("TLS settings: %w", err)
 		}
 	}
 
 	return nil
 }
+
+// listeningAddresses returns the listening addresses to use.
+func (s Settings) listeningAddresses() (addresses []string) {
+	switch {
+	case len(s.Addresses) > 0:
+		return s.Addresses
+	case s.Address != nil:
+		return []string{*s.Address}
+	default:
+		return nil
+	}
+}
@@ -1,20 +1,18 @@
 package httpserver
 
 import (
+	"crypto/tls"
+	"net"
 	"net/http"
-	reflect "reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 )
 
 func Test_Settings_SetDefaults(t *testing.T) {
 	t.Parallel()
 
-	cancelHandler := func() {}
-
 	testCases := map[string]struct {
 		settings         Settings
 		expectedSettings Settings
@@ -27,7 +25,19 @@ func Test_Settings_SetDefaults(t *testing.T) {
 				ReadTimeout:       10 * time.Second,
 				ReadHeaderTimeout: time.Second,
 				Logger:            &noopLogger{},
-				CancelHandler:     func() {},
+			},
+		},
+		"inherited listener only": {
+			settings: Settings{
+				FileDescriptors: []uintptr{3},
+			},
+			expectedSettings: Settings{
+				Name:              stringPtr(""),
+				FileDescriptors:   []uintptr{3},
+				ShutdownTimeout:   3 * time.Second,
+				ReadTimeout:       10 * time.Second,
+				ReadHeaderTimeout: time.Second,
+				Logger:            &noopLogger{},
 			},
 		},
 		"all settings fields set": {
@@ -39,7 +49,6 @@ func Test_Settings_SetDefaults(t *testing.T) {
 				ReadHeaderTimeout: 2 * time.Second,
 				ShutdownTimeout:   3 * time.Second,
 				Logger:            NewMockInfoer(nil),
-				CancelHandler:     cancelHandler,
 			},
 			expectedSettings: Settings{
 				Name:              stringPtr("x"),
@@ -49,7 +58,6 @@ func Test_Settings_SetDefaults(t *testing.T) {
 				ReadHeaderTimeout: 2 * time.Second,
 				ShutdownTimeout:   3 * time.Second,
 				Logger:            NewMockInfoer(nil),
-				CancelHandler:     cancelHandler,
 			},
 		},
 	}
@@ -58,20 +66,8 @@ func Test_Settings_SetDefaults(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			originalCancelHandler := testCase.settings.CancelHandler
-
 			testCase.settings.SetDefaults()
 
-			require.NotNil(t, testCase.settings.CancelHandler)
-			if originalCancelHandler != nil {
-				assert.Equal(t,
-					reflect.ValueOf(originalCancelHandler).Pointer(),
-					reflect.ValueOf(testCase.expectedSettings.CancelHandler).Pointer())
-			}
-			// Remove function pointer before comparison
-			testCase.expectedSettings.CancelHandler = nil
-			testCase.settings.CancelHandler = nil
-
 			assert.Equal(t, testCase.expectedSettings, testCase.settings)
 		})
 	}
@@ -94,6 +90,52 @@ func Test_Settings_Validate(t *testing.T) {
 			},
 			errMessage: "listening address is not valid: address -1: invalid port",
 		},
+		"address and addresses set": {
+			settings: Settings{
+				Handler:   http.NewServeMux(),
+				Address:   stringPtr(":0"),
+				Addresses: []string{":0"},
+			},
+			errMessage: "address and addresses cannot be both set",
+		},
+		"empty unix socket path": {
+			settings: Settings{
+				Handler:   http.NewServeMux(),
+				Addresses: []string{":0", "unix:"},
+			},
+			errMessage: "unix socket path is empty: unix:",
+		},
+		"nil listener": {
+			settings: Settings{
+				Handler:   http.NewServeMux(),
+				Address:   stringPtr(":0"),
+				Listeners: []net.Listener{nil},
+			},
+			errMessage: "listener is nil: at index 0",
+		},
+		"TLS without certificate": {
+			settings: Settings{
+				Handler: http.NewServeMux(),
+				Address: stringPtr(":0"),
+				TLS: &TLSSettings{
+					MinVersion:      tls.VersionTLS12,
+					RedirectAddress: stringPtr(""),
+				},
+			},
+			errMessage: "TLS settings: no TLS certificate specified",
+		},
+		"TLS certificate without key": {
+			settings: Settings{
+				Handler: http.NewServeMux(),
+				Address: stringPtr(":0"),
+				TLS: &TLSSettings{
+					CertFile:        "cert.pem",
+					MinVersion:      tls.VersionTLS12,
+					RedirectAddress: stringPtr(""),
+				},
+			},
+			errMessage: "TLS settings: certificate file and key file must be set together",
+		},
 		"valid settings": {
 			settings: Settings{
 				Handler: http.NewServeMux(),
@@ -0,0 +1,72 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrListenFDsNotValid = errors.New("LISTEN_FDS value is not valid")
+
+// listenFDsStart is the first file descriptor passed
+// by systemd socket activation.
+const listenFDsStart = 3
+
+// ListenersFromSystemd returns the listeners passed by systemd socket
+// activation, keyed by their name set with `FileDescriptorName=` in the
+// systemd socket unit, or named "unknown" if no name is set.
+// It returns an empty map if the process was not socket activated.
+// If unsetEnvironment is true, the `LISTEN_PID`, `LISTEN_FDS` and
+// `LISTEN_FDNAMES` environment variables are unset, so they are not
+// inherited by child processes.
+func ListenersFromSystemd(unsetEnvironment bool) (listeners map[string][]net.Listener, err error) {
+	if unsetEnvironment {
+		defer func() {
+			_ = os.Unsetenv("LISTEN_PID")
+			_ = os.Unsetenv("LISTEN_FDS")
+			_ = os.Unsetenv("LISTEN_FDNAMES")
+		}()
+	}
+
+	listeners = make(map[string][]net.Listener)
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return listeners, nil //nolint:nilerr
+	}
+
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrListenFDsNotValid, os.Getenv("LISTEN_FDS"))
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := range count {
+		fd := listenFDsStart + i
+
+		name := "unknown"
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(fd), name)
+		// The listener uses a duplicate file descriptor with close-on-exec
+		// set, so closing the inherited one keeps it from child processes.
+		listener, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			for _, namedListeners := range listeners {
+				for _, listener := range namedListeners {
+					_ = listener.Close()
+				}
+			}
+			return nil, fmt.Errorf("creating listener from file descriptor %d named %s: %w",
+				fd, name, err)
+		}
+		listeners[name] = append(listeners[name], listener)
+	}
+
+	return listeners, nil
+}
@@ -0,0 +1,210 @@
+package httpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrClientCANoCertificate = errors.New("no certificate found in client CA file")
+
+// newTLSConfig creates the TLS configuration for the TLS settings given.
+func newTLSConfig(settings TLSSettings) (config *tls.Config, err error) {
+	if settings.Config == nil {
+		config = &tls.Config{} //nolint:gosec
+	} else {
+		config = settings.Config.Clone()
+	}
+	// Do not weaken a stricter minimum version of the base configuration.
+	config.MinVersion = max(config.MinVersion, settings.MinVersion)
+
+	switch {
+	case settings.CertFile != "":
+		reloader, err := newCertificateReloader(settings.CertFile,
+			settings.KeyFile, settings.ReloadPeriod)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = nil
+		config.GetCertificate = reloader.getCertificate
+	case len(config.Certificates) == 0 && config.GetCertificate == nil:
+		// Self signed certificate, as enforced by settings validation.
+		certificate, err := newSelfSignedCertificate()
+		if err != nil {
+			return nil, fmt.Errorf("generating self-signed certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	if settings.ClientCAFile != "" {
+		pemData, err := os.ReadFile(settings.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, fmt.Errorf("%w: %s", ErrClientCANoCertificate, settings.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// certificateReloader reloads a certificate and its key when
+// their files modification time or size change on disk.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+	period   time.Duration
+	timeNow  func() time.Time
+
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	lastCheck   time.Time
+	certStat    fileStat
+	keyStat     fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func newCertificateReloader(certFile, keyFile string, period time.Duration) (
+	reloader *certificateReloader, err error) {
+	reloader = &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		period:   period,
+		timeNow:  time.Now,
+	}
+	reloader.certStat, reloader.keyStat, err = reloader.stat()
+	if err != nil {
+		return nil, err
+	}
+	err = reloader.load()
+	if err != nil {
+		return nil, err
+	}
+	reloader.lastCheck = reloader.timeNow()
+	return reloader, nil
+}
+
+func (c *certificateReloader) stat() (certStat, keyStat fileStat, err error) {
+	info, err := os.Stat(c.certFile)
+	if err != nil {
+		return certStat, keyStat, fmt.Errorf("certificate file: %w", err)
+	}
+	certStat = fileStat{modTime: info.ModTime(), size: info.Size()}
+	info, err = os.Stat(c.keyFile)
+	if err != nil {
+		return certStat, keyStat, fmt.Errorf("key file: %w", err)
+	}
+	keyStat = fileStat{modTime: info.ModTime(), size: info.Size()}
+	return certStat, keyStat, nil
+}
+
+func (c *certificateReloader) load() (err error) {
+	certificate, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading certificate: %w", err)
+	}
+	c.certificate = &certificate
+	return nil
+}
+
+// getCertificate returns the current certificate, reloading it
+// first if its files changed since the last check. If reloading
+// fails, for example because only one of the two files was updated
+// so far, the previous certificate is kept and the reload is retried
+// at the next check.
+func (c *certificateReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.timeNow()
+	if now.Sub(c.lastCheck) < c.period {
+		return c.certificate, nil
+	}
+	c.lastCheck = now
+
+	certStat, keyStat, err := c.stat()
+	if err != nil || (certStat == c.certStat && keyStat == c.keyStat) {
+		return c.certificate, nil //nolint:nilerr
+	}
+
+	err = c.load()
+	if err == nil {
+		c.certStat, c.keyStat = certStat, keyStat
+	}
+	return c.certificate, nil
+}
+
+// newSelfSignedCertificate generates a self-signed certificate
+// valid for localhost, for development purposes only.
+func newSelfSignedCertificate() (certificate tls.Certificate, err error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return certificate, fmt.Errorf("generating private key: %w", err)
+	}
+
+	const serialNumberBits = 128
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialNumberBits))
+	if err != nil {
+		return certificate, fmt.Errorf("generating serial number: %w", err)
+	}
+
+	const validity = 365 * 24 * time.Hour
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template,
+		&privateKey.PublicKey, privateKey)
+	if err != nil {
+		return certificate, fmt.Errorf("creating certificate: %w", err)
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{certDER},
+		PrivateKey:  privateKey,
+	}, nil
+}
+
+// newRedirectHandler returns a handler redirecting requests
+// to the same URL using HTTPS, on the port given.
+func newRedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host // no port in host
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// TLSSettings is the settings for serving HTTPS.
+type TLSSettings struct {
+	// CertFile is the path to the PEM encoded certificate file.
+	// It must be set together with `KeyFile`, and the certificate
+	// and key are reloaded when either file changes on disk.
+	// It defaults to the empty string.
+	CertFile string
+	// KeyFile is the path to the PEM encoded private key file.
+	// It must be set together with `CertFile`.
+	// It defaults to the empty string.
+	KeyFile string
+	// Config is a base TLS configuration, which is cloned and
+	// completed with the other settings. It can notably be used to
+	// set certificates or a `GetCertificate` function directly.
+	// It defaults to nil, meaning an empty base configuration.
+	Config *tls.Config
+	// ClientCAFile is the path to a PEM encoded file of certificate
+	// authorities used to verify client certificates. If set, clients
+	// must present a valid certificate (mutual TLS).
+	// It defaults to the empty string, meaning no client verification.
+	ClientCAFile string
+	// MinVersion is the minimum TLS version accepted. The minimum
+	// version of `Config`, if set and stricter, takes precedence.
+	// It defaults to `tls.VersionTLS12` if left unset.
+	MinVersion uint16
+	// SelfSigned generates a self-signed certificate for localhost,
+	// for development purposes only, if no certificate is set.
+	// It defaults to false.
+	SelfSigned bool
+	// ReloadPeriod is the minimum period between two checks
+	// of the certificate and key files modification.
+	// It defaults to 5 seconds if left unset.
+	ReloadPeriod time.Duration
+	// RedirectAddress, if not empty, is the listening address of a
+	// companion plain HTTP server redirecting all requests to HTTPS.
+	// It defaults to the empty string, meaning no redirect server.
+	RedirectAddress *string
+}
+
+// SetDefaults sets the default values for the TLS settings.
+func (t *TLSSettings) SetDefaults() {
+	if t.MinVersion == 0 {
+		t.MinVersion = tls.VersionTLS12
+	}
+
+	if t.ReloadPeriod == 0 {
+		const defaultReloadPeriod = 5 * time.Second
+		t.ReloadPeriod = defaultReloadPeriod
+	}
+
+	if t.RedirectAddress == nil {
+		t.RedirectAddress = new(string)
+	}
+}
+
+var (
+	ErrTLSCertKeyMismatch     = errors.New("certificate file and key file must be set together")
+	ErrTLSNoCertificate       = errors.New("no TLS certificate specified")
+	ErrTLSMinVersionNotValid  = errors.New("minimum TLS version is not valid")
+	ErrTLSReloadPeriodInvalid = errors.New("reload period is not valid")
+)
+
+// Validate validates the TLS settings and returns an error
+// if any setting is not valid.
+func (t TLSSettings) Validate() (err error) {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("%w", ErrTLSCertKeyMismatch)
+	}
+
+	hasConfigCertificate := t.Config != nil &&
+		(len(t.Config.Certificates) > 0 || t.Config.GetCertificate != nil)
+	if t.CertFile == "" && !hasConfigCertificate && !t.SelfSigned {
+		return fmt.Errorf("%w", ErrTLSNoCertificate)
+	}
+
+	if t.MinVersion < tls.VersionTLS10 || t.MinVersion > tls.VersionTLS13 {
+		return fmt.Errorf("%w: 0x%04x", ErrTLSMinVersionNotValid, t.MinVersion)
+	}
+
+	if t.ReloadPeriod < 0 {
+		return fmt.Errorf("%w: %s", ErrTLSReloadPeriodInvalid, t.ReloadPeriod)
+	}
+
+	if *t.RedirectAddress != "" {
+		_, err = net.ResolveTCPAddr("tcp", *t.RedirectAddress)
+		if err != nil {
+			return fmt.Errorf("redirect listening address is not valid: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCertificate generates a self-signed certificate with the
+// serial number and extended key usage given, writes its PEM encoded
+// certificate and key files in the directory given, and returns the
+// certificate and the files paths.
+func writeCertificate(t *testing.T, directory string, serial int64,
+	extKeyUsage x509.ExtKeyUsage) (certificate tls.Certificate, certFile, keyFile string) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{extKeyUsage},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template,
+		&privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(privateKey)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(directory, "cert.pem")
+	keyFile = filepath.Join(directory, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+
+	certificate, err = tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	return certificate, certFile, keyFile
+}
+
+func Test_newTLSConfig_MinVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		configMinVersion   uint16
+		settingsMinVersion uint16
+		minVersion         uint16
+	}{
+		"config_unset": {
+			settingsMinVersion: tls.VersionTLS12,
+			minVersion:         tls.VersionTLS12,
+		},
+		"config_stricter": {
+			configMinVersion:   tls.VersionTLS13,
+			settingsMinVersion: tls.VersionTLS12,
+			minVersion:         tls.VersionTLS13,
+		},
+		"settings_stricter": {
+			configMinVersion:   tls.VersionTLS10,
+			settingsMinVersion: tls.VersionTLS12,
+			minVersion:         tls.VersionTLS12,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			baseConfig := &tls.Config{ //nolint:gosec
+				MinVersion:   testCase.configMinVersion,
+				Certificates: []tls.Certificate{{}},
+			}
+
+			config, err := newTLSConfig(TLSSettings{
+				Config:     baseConfig,
+				MinVersion: testCase.settingsMinVersion,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.minVersion, config.MinVersion)
+			assert.Equal(t, testCase.configMinVersion, baseConfig.MinVersion)
+		})
+	}
+}
+
+func Test_certificateReloader(t *testing.T) {
+	t.Parallel()
+
+	directory := t.TempDir()
+	_, certFile, keyFile := writeCertificate(t, directory, 1, x509.ExtKeyUsageServerAuth)
+
+	reloader, err := newCertificateReloader(certFile, keyFile, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	reloader.timeNow = func() time.Time { return now }
+
+	serialOf := func() int64 {
+		certificate, err := reloader.getCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+		require.NoError(t, err)
+		return leaf.SerialNumber.Int64()
+	}
+
+	assert.Equal(t, int64(1), serialOf())
+
+	// Rewrite the files with a different size and modification time.
+	writeCertificate(t, directory, 1234567890, x509.ExtKeyUsageServerAuth)
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	// Files are not checked again before the reload period elapses.
+	assert.Equal(t, int64(1), serialOf())
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, int64(1234567890), serialOf())
+
+	// A broken key file keeps the previous certificate.
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	now = now.Add(time.Minute)
+	assert.Equal(t, int64(1234567890), serialOf())
+}
+
+func Test_Server_TLS(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server, err := New(Settings{
+		Handler: handler,
+		Address: stringPtr("127.0.0.1:0"),
+		TLS: &TLSSettings{
+			SelfSigned:      true,
+			RedirectAddress: stringPtr("127.0.0.1:0"),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, server.Stop())
+	}()
+
+	client := &http.Client{
+		Timeout: time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	response, err := client.Get("https://" + server.GetAddress()) //nolint:noctx
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, err = client.Get("http://" + server.GetRedirectAddress() + "/path?x=1") //nolint:noctx
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusPermanentRedirect, response.StatusCode)
+	assert.Equal(t, "https://"+server.GetAddress()+"/path?x=1", response.Header.Get("Location"))
+}
+
+func Test_Server_mutualTLS(t *testing.T) {
+	t.Parallel()
+
+	serverCertificate, _, _ := writeCertificate(t, t.TempDir(), 1, x509.ExtKeyUsageServerAuth)
+	clientCertificate, clientCAFile, _ := writeCertificate(t, t.TempDir(), 2, x509.ExtKeyUsageClientAuth)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server, err := New(Settings{
+		Handler: handler,
+		Address: stringPtr("127.0.0.1:0"),
+		TLS: &TLSSettings{
+			Config:       &tls.Config{Certificates: []tls.Certificate{serverCertificate}}, //nolint:gosec
+			ClientCAFile: clientCAFile,
+			MinVersion:   tls.VersionTLS13,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, server.Stop())
+	}()
+
+	newClient := func(certificates []tls.Certificate) *http.Client {
+		return &http.Client{
+			Timeout: time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{ //nolint:gosec
+					InsecureSkipVerify: true,
+					Certificates:       certificates,
+				},
+			},
+		}
+	}
+
+	_, err = newClient(nil).Get("https://" + server.GetAddress()) //nolint:noctx,bodyclose
+	assert.Error(t, err)
+
+	response, err := newClient([]tls.Certificate{clientCertificate}).
+		Get("https://" + server.GetAddress()) //nolint:noctx
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
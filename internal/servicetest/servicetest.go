@@ -0,0 +1,25 @@
+// Package servicetest provides a service to use in tests of
+// packages depending on the goservices package.
+package servicetest
+
+import (
+	"context"
+
+	"github.com/qdm12/goservices"
+)
+
+// New returns a service running until it is stopped, or until
+// it crashes with an error received from `crash` if it is not nil.
+func New(name string, crash <-chan error) *goservices.RunWrapper {
+	return goservices.NewRunWrapper(name, func(ctx context.Context,
+		ready chan<- struct{}, runError, stopError chan<- error) {
+		close(ready)
+		select {
+		case <-ctx.Done():
+			close(stopError)
+		case err := <-crash:
+			runError <- err
+			close(runError)
+		}
+	})
+}
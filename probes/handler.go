@@ -0,0 +1,171 @@
+package probes
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/qdm12/goservices"
+)
+
+type check struct {
+	name   string
+	reason string // empty if the check passed
+}
+
+// ServeHTTP serves the `/livez`, `/readyz` and `/startupz` probes,
+// matched on the suffix of the request path, and responds with a
+// 404 status for any other path.
+//
+// A probe responds with a 200 status if it passes, and with a 500
+// status otherwise. With the `verbose` query parameter set, each
+// individual check is written in the response body, in the format
+// used by the Kubernetes API server. Checks can be excluded with
+// one or more `exclude` query parameters.
+//
+// - `/startupz` passes once the root service start has returned
+// without error.
+// - `/livez` fails if any service crashed and was not restarted.
+// - `/readyz` fails if the root service is not running, or if any
+// service not set as optional is not running, which is notably the
+// case whilst it restarts, or if the root service implements
+// `goservices.Readier` and is not ready.
+func (p *Probes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var probe string
+	var checks []check
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/startupz"):
+		probe, checks = "startupz", p.startupChecks()
+	case strings.HasSuffix(r.URL.Path, "/livez"):
+		probe, checks = "livez", p.livenessChecks()
+	case strings.HasSuffix(r.URL.Path, "/readyz"):
+		probe, checks = "readyz", p.readinessChecks()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	excluded := query["exclude"]
+	_, verbose := query["verbose"]
+
+	buffer := new(bytes.Buffer)
+	passed := true
+	for _, result := range checks {
+		switch {
+		case slices.Contains(excluded, result.name):
+			fmt.Fprintf(buffer, "[+]%s excluded: ok\n", result.name)
+		case result.reason == "":
+			fmt.Fprintf(buffer, "[+]%s ok\n", result.name)
+		default:
+			passed = false
+			fmt.Fprintf(buffer, "[-]%s failed: %s\n", result.name, result.reason)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	status := http.StatusOK
+	summary := probe + " check passed"
+	if !passed {
+		status = http.StatusInternalServerError
+		summary = probe + " check failed"
+	}
+	w.WriteHeader(status)
+
+	switch {
+	case verbose:
+		buffer.WriteString(summary + "\n")
+		_, _ = w.Write(buffer.Bytes())
+	case passed:
+		_, _ = w.Write([]byte("ok"))
+	default:
+		_, _ = w.Write([]byte(summary + "\n"))
+	}
+}
+
+func (p *Probes) startupChecks() (checks []check) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	startCheck := check{name: "start"}
+	if !p.started {
+		startCheck.reason = "root service not started"
+	}
+	return []check{startCheck}
+}
+
+func (p *Probes) livenessChecks() (checks []check) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	services := p.sortedServices()
+	checks = make([]check, len(services))
+	for i, service := range services {
+		checks[i].name = service
+		state := p.worst(service)
+		if state.state == goservices.StateCrashed {
+			checks[i].reason = stateReason(state)
+		}
+	}
+	return checks
+}
+
+func (p *Probes) readinessChecks() (checks []check) {
+	p.mutex.RLock()
+	services := p.sortedServices()
+	checks = make([]check, 0, len(services)+1)
+	for _, service := range services {
+		if service != p.root && slices.Contains(p.optional, service) {
+			continue
+		}
+
+		serviceCheck := check{name: service}
+		state := p.worst(service)
+		if state.state != goservices.StateRunning {
+			serviceCheck.reason = stateReason(state)
+		}
+		checks = append(checks, serviceCheck)
+	}
+
+	rootRunning := p.root != "" && p.services[p.root] != nil &&
+		p.worst(p.root).state == goservices.StateRunning
+	if p.root == "" || p.services[p.root] == nil {
+		checks = append(checks, check{name: "root", reason: "root service not started"})
+	}
+	readier := p.readier
+	p.mutex.RUnlock()
+
+	// Only check the readiness of the root service when it is running,
+	// since it is otherwise already reported as failing above.
+	if readier != nil && rootRunning {
+		readyCheck := check{name: "readiness"}
+		err := readier.Ready()
+		if err != nil {
+			readyCheck.reason = err.Error()
+		}
+		checks = append(checks, readyCheck)
+	}
+
+	return checks
+}
+
+func (p *Probes) sortedServices() (services []string) {
+	services = make([]string, 0, len(p.services))
+	for service := range p.services {
+		services = append(services, service)
+	}
+	slices.Sort(services)
+	return services
+}
+
+func stateReason(state *serviceState) string {
+	if state.err == nil {
+		return state.state.String()
+	}
+	// Keep each check on a single line.
+	return state.state.String() + ": " + strings.ReplaceAll(state.err.Error(), "\n", " ")
+}
@@ -0,0 +1,128 @@
+// Package probes implements Kubernetes-style liveness, readiness
+// and startup probes HTTP handlers, answered from the state of a
+// service tree.
+package probes
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Hooks = (*Probes)(nil)
+
+// Probes tracks the state of each service of a service tree using
+// hooks events, and serves the `/livez`, `/readyz` and `/startupz`
+// probes from it. It must be set as the hooks of each service
+// management type of the tree, and the root service of the tree
+// must be wrapped with `Wrap`.
+type Probes struct {
+	optional []string
+
+	mutex    sync.RWMutex
+	root     string
+	started  bool
+	services map[string][]*serviceState
+	readier  goservices.Readier
+}
+
+// serviceState is the state of a service instance. Hooks only give
+// the service name, so services with the same name in different
+// subtrees, or nested with the same name such as a service wrapped
+// in a `Restarter`, each have their own instance under that name.
+type serviceState struct {
+	state goservices.State
+	err   error
+}
+
+// New creates a new probes handler using the settings given.
+func New(settings Settings) (probes *Probes, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &Probes{
+		optional: slices.Clone(settings.Optional),
+		services: make(map[string][]*serviceState),
+	}, nil
+}
+
+// transition sets the state of the first instance of the service
+// in the first of the `from` states matching an instance. If there is
+// no such instance, a new instance is recorded for the service, so an
+// event from a service never overwrites the state of another service
+// with the same name in a state not expected for this event.
+func (p *Probes) transition(service string, from []goservices.State,
+	to goservices.State, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, fromState := range from {
+		for _, instance := range p.services[service] {
+			if instance.state == fromState {
+				instance.state, instance.err = to, err
+				return
+			}
+		}
+	}
+	p.services[service] = append(p.services[service], &serviceState{state: to, err: err})
+}
+
+// worst returns the crashed instance of the service if any,
+// otherwise its first instance not running if any, and
+// otherwise its first instance.
+// It must be called with the mutex locked.
+func (p *Probes) worst(service string) (state *serviceState) {
+	instances := p.services[service]
+	for _, instance := range instances {
+		if instance.state == goservices.StateCrashed {
+			return instance
+		}
+	}
+	for _, instance := range instances {
+		if instance.state != goservices.StateRunning {
+			return instance
+		}
+	}
+	return instances[0]
+}
+
+// OnStart records the service as starting.
+func (p *Probes) OnStart(service string) {
+	// Prefer a crashed instance, which is restarted by a `Restarter`.
+	p.transition(service, []goservices.State{goservices.StateCrashed, goservices.StateStopped},
+		goservices.StateStarting, nil)
+}
+
+// OnStarted records the service as running, or as crashed if it
+// failed to start, since a start failure of an already started
+// tree, such as a failed restart, cannot recover on its own.
+func (p *Probes) OnStarted(service string, err error) {
+	from := []goservices.State{goservices.StateStarting}
+	if err != nil {
+		p.transition(service, from, goservices.StateCrashed, fmt.Errorf("starting: %w", err))
+		return
+	}
+	p.transition(service, from, goservices.StateRunning, nil)
+}
+
+// OnStop records the service as stopping.
+func (p *Probes) OnStop(service string) {
+	p.transition(service, []goservices.State{goservices.StateRunning, goservices.StateCrashed},
+		goservices.StateStopping, nil)
+}
+
+// OnStopped records the service as stopped, with its stop error if any.
+func (p *Probes) OnStopped(service string, err error) {
+	p.transition(service, []goservices.State{goservices.StateStopping},
+		goservices.StateStopped, err)
+}
+
+// OnCrash records the service as crashed with its crash error.
+func (p *Probes) OnCrash(service string, err error) {
+	p.transition(service, []goservices.State{goservices.StateRunning},
+		goservices.StateCrashed, err)
+}
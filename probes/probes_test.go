@@ -0,0 +1,171 @@
+package probes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/qdm12/goservices/internal/servicetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(t *testing.T, handler http.Handler, target string) (status int, body string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	handler.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+func Test_Probes(t *testing.T) {
+	t.Parallel()
+
+	probes, err := New(Settings{Optional: []string{"B"}})
+	require.NoError(t, err)
+
+	crash := make(chan error)
+	group, err := goservices.NewGroup(goservices.GroupSettings{
+		Name:     "g",
+		Services: []goservices.Service{servicetest.New("A", crash), servicetest.New("B", nil)},
+		Hooks:    probes,
+	})
+	require.NoError(t, err)
+	root := probes.Wrap(group)
+
+	status, body := probe(t, probes, "/startupz")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "startupz check failed\n", body)
+
+	status, body = probe(t, probes, "/readyz?verbose")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "[-]root failed: root service not started\nreadyz check failed\n", body)
+
+	status, body = probe(t, probes, "/livez")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+
+	runError, err := root.Start(context.Background())
+	require.NoError(t, err)
+
+	status, body = probe(t, probes, "/startupz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ok", body)
+
+	status, body = probe(t, probes, "/readyz?verbose")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "[+]A ok\n[+]group g ok\n[+]readiness ok\nreadyz check passed\n", body)
+
+	crash <- errors.New("boom")
+	err = <-runError
+	require.EqualError(t, err, "A crashed: boom")
+
+	status, body = probe(t, probes, "/livez?verbose")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "[-]A failed: crashed: boom\n"+
+		"[+]B ok\n"+
+		"[-]group g failed: crashed: A crashed: boom\n"+
+		"livez check failed\n", body)
+
+	status, body = probe(t, probes, "/livez?verbose&exclude=A&exclude=group+g")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "[+]A excluded: ok\n[+]B ok\n[+]group g excluded: ok\nlivez check passed\n", body)
+
+	status, _ = probe(t, probes, "/readyz")
+	assert.Equal(t, http.StatusInternalServerError, status)
+
+	err = root.Stop()
+	require.NoError(t, err)
+
+	status, _ = probe(t, probes, "/unknown")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func Test_Probes_duplicateNames(t *testing.T) {
+	t.Parallel()
+
+	probes, err := New(Settings{})
+	require.NoError(t, err)
+
+	crash := make(chan error)
+	newGroup := func(name string, crash <-chan error) *goservices.Group {
+		group, err := goservices.NewGroup(goservices.GroupSettings{
+			Name:     name,
+			Services: []goservices.Service{servicetest.New("A", crash)},
+			Hooks:    probes,
+		})
+		require.NoError(t, err)
+		return group
+	}
+	group, err := goservices.NewGroup(goservices.GroupSettings{
+		Name:     "root",
+		Services: []goservices.Service{newGroup("1", crash), newGroup("2", nil)},
+		Hooks:    probes,
+	})
+	require.NoError(t, err)
+	root := probes.Wrap(group)
+
+	runError, err := root.Start(context.Background())
+	require.NoError(t, err)
+
+	// The crash of the first service named A is not overwritten
+	// by the second service named A being stopped.
+	crash <- errors.New("boom")
+	<-runError
+
+	status, body := probe(t, probes, "/livez?verbose&exclude=group+1&exclude=group+root")
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "[-]A failed: crashed: boom\n"+
+		"[+]group 1 excluded: ok\n"+
+		"[+]group 2 ok\n"+
+		"[+]group root excluded: ok\n"+
+		"livez check failed\n", body)
+
+	err = root.Stop()
+	require.NoError(t, err)
+}
+
+type testReadyDrainService struct {
+	*goservices.RunWrapper
+	drained chan struct{}
+}
+
+func (s *testReadyDrainService) Ready() error { return errors.New("warming up") }
+
+func (s *testReadyDrainService) Drain(context.Context) { close(s.drained) }
+
+func Test_Service_forwarding(t *testing.T) {
+	t.Parallel()
+
+	probes, err := New(Settings{})
+	require.NoError(t, err)
+
+	service := &testReadyDrainService{
+		RunWrapper: servicetest.New("A", nil),
+		drained:    make(chan struct{}),
+	}
+	wrapped := probes.Wrap(service)
+
+	err = wrapped.Ready()
+	assert.EqualError(t, err, "warming up")
+
+	sequence, err := goservices.NewSequence(goservices.SequenceSettings{
+		ServicesStart: []goservices.Service{wrapped},
+		ServicesStop:  []goservices.Service{wrapped},
+		DrainDelay:    time.Hour,
+	})
+	require.NoError(t, err)
+
+	_, err = sequence.Start(context.Background())
+	require.NoError(t, err)
+
+	// The drain phase returns as soon as the drainer wrapped
+	// is drained, instead of waiting for the drain delay.
+	err = sequence.Stop()
+	require.NoError(t, err)
+	<-service.drained
+}
@@ -0,0 +1,24 @@
+package probes
+
+// Settings is the settings for the probes handler.
+type Settings struct {
+	// Optional are the names of optional services, which are
+	// excluded from the readiness check. Services can also be
+	// excluded for a single request using the `exclude` query
+	// parameter, for example `/readyz?exclude=A&exclude=B`.
+	// It defaults to an empty slice.
+	Optional []string
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Optional == nil {
+		s.Optional = []string{}
+	}
+}
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	return nil
+}
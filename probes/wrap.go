@@ -0,0 +1,96 @@
+package probes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Service = (*Service)(nil)
+
+// Service wraps the root service of a tree to track its own state,
+// including crashes, and whether its start has returned.
+type Service struct {
+	goservices.Forwarder
+	service        goservices.Service
+	probes         *Probes
+	startStopMutex sync.Mutex
+	interceptStop  chan struct{}
+	interceptDone  chan struct{}
+}
+
+// Wrap returns a service wrapping the root service given.
+// If the root service implements `goservices.Readier`, its
+// readiness is also checked by the readiness probe. The readiness
+// and the drain phase are forwarded to the root service.
+func (p *Probes) Wrap(root goservices.Service) *Service {
+	p.mutex.Lock()
+	p.root = root.String()
+	p.readier, _ = root.(goservices.Readier)
+	p.mutex.Unlock()
+
+	return &Service{
+		Forwarder: goservices.NewForwarder(root),
+		service:   root,
+		probes:    p,
+	}
+}
+
+func (s *Service) String() string {
+	return s.service.String()
+}
+
+// Start starts the root service, and marks the startup
+// probe as passing if it started successfully.
+func (s *Service) Start(ctx context.Context) (runError <-chan error, startErr error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	serviceString := s.service.String()
+	s.probes.OnStart(serviceString)
+	serviceRunError, startErr := s.service.Start(ctx)
+	s.probes.OnStarted(serviceString, startErr)
+	if startErr != nil {
+		return nil, startErr
+	}
+
+	s.probes.mutex.Lock()
+	s.probes.started = true
+	s.probes.mutex.Unlock()
+
+	runErrorCh := make(chan error)
+	s.interceptStop = make(chan struct{})
+	s.interceptDone = make(chan struct{})
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		select {
+		case <-stop:
+		case err := <-serviceRunError:
+			s.probes.OnCrash(serviceString, err)
+			runErrorCh <- err
+			close(runErrorCh)
+		}
+	}(s.interceptStop, s.interceptDone)
+
+	return runErrorCh, nil
+}
+
+// Stop stops the root service, failing the readiness probe
+// as soon as the root service starts stopping.
+func (s *Service) Stop() (err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	serviceString := s.service.String()
+	s.probes.OnStop(serviceString)
+	err = s.service.Stop()
+	s.probes.OnStopped(serviceString, err)
+
+	if s.interceptStop != nil {
+		close(s.interceptStop)
+		<-s.interceptDone
+		s.interceptStop = nil
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package goservices
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+const (
+	// ProfilerLabelService is the pprof label key set to the
+	// service path, for example "sequence s/group g/A", on
+	// goroutines spawned by service management types when
+	// profiler labels are enabled.
+	ProfilerLabelService = "goservices.service"
+	// ProfilerLabelPhase is the pprof label key set to the
+	// service phase, which is one of "start", "stop" or "run",
+	// on goroutines spawned by service management types when
+	// profiler labels are enabled.
+	ProfilerLabelPhase = "goservices.phase"
+)
+
+const (
+	phaseStart = "start"
+	phaseStop  = "stop"
+	phaseRun   = "run"
+)
+
+// profiler sets pprof goroutine labels and creates runtime/trace
+// tasks and regions for service operations, if enabled.
+type profiler struct {
+	enabled bool
+	// path is the service path of the service owning the profiler.
+	path string
+}
+
+// newProfiler returns a profiler for the service named `name`.
+// If the context given has a service profiler label set by a parent
+// service management type, the profiler is enabled regardless of the
+// `enabled` argument and uses the label value as its service path.
+func newProfiler(ctx context.Context, enabled bool, name string) profiler {
+	path, ok := pprof.Label(ctx, ProfilerLabelService)
+	if ok {
+		return profiler{enabled: true, path: path}
+	}
+	return profiler{enabled: enabled, path: name}
+}
+
+// childPath returns the service path of a child service.
+func (p profiler) childPath(child string) string {
+	return p.path + "/" + child
+}
+
+// do runs `f` with pprof goroutine labels set for the service path
+// and phase given, and within a runtime/trace task and region.
+// If the profiler is disabled, `f` is simply called with `ctx`.
+func (p profiler) do(ctx context.Context, path, phase string,
+	f func(ctx context.Context)) {
+	if !p.enabled {
+		f(ctx)
+		return
+	}
+
+	ctx, task := trace.NewTask(ctx, phase+" "+path)
+	defer task.End()
+	pprof.Do(ctx, profilerLabels(path, phase), func(ctx context.Context) {
+		trace.WithRegion(ctx, phase, func() {
+			f(ctx)
+		})
+	})
+}
+
+// labelGoroutine sets the pprof labels of the calling goroutine
+// to the profiler service path and phase given, if enabled.
+func (p profiler) labelGoroutine(phase string) {
+	if !p.enabled {
+		return
+	}
+	pprof.SetGoroutineLabels(pprof.WithLabels(context.Background(),
+		profilerLabels(p.path, phase)))
+}
+
+func profilerLabels(path, phase string) pprof.LabelSet {
+	return pprof.Labels(ProfilerLabelService, path, ProfilerLabelPhase, phase)
+}
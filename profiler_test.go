@@ -0,0 +1,110 @@
+package goservices
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newProfiler(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		ctx      context.Context
+		enabled  bool
+		profiler profiler
+	}{
+		"disabled": {
+			ctx:      context.Background(),
+			profiler: profiler{path: "name"},
+		},
+		"enabled": {
+			ctx:      context.Background(),
+			enabled:  true,
+			profiler: profiler{enabled: true, path: "name"},
+		},
+		"enabled by parent": {
+			ctx: pprof.WithLabels(context.Background(),
+				profilerLabels("parent/name", phaseStart)),
+			profiler: profiler{enabled: true, path: "parent/name"},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			profiler := newProfiler(testCase.ctx, testCase.enabled, "name")
+
+			assert.Equal(t, testCase.profiler, profiler)
+		})
+	}
+}
+
+func Test_profiler_do(t *testing.T) {
+	t.Parallel()
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		profiler := profiler{path: "parent"}
+
+		profiler.do(ctx, "parent/child", phaseStart, func(fCtx context.Context) {
+			assert.Equal(t, ctx, fCtx)
+		})
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Parallel()
+
+		profiler := profiler{enabled: true, path: "parent"}
+
+		profiler.do(context.Background(), "parent/child", phaseStop, func(ctx context.Context) {
+			path, _ := pprof.Label(ctx, ProfilerLabelService)
+			assert.Equal(t, "parent/child", path)
+			phase, _ := pprof.Label(ctx, ProfilerLabelPhase)
+			assert.Equal(t, phaseStop, phase)
+		})
+	})
+}
+
+func Test_profilerLabels_tree(t *testing.T) {
+	t.Parallel()
+
+	labels := make(chan [2]string, 1)
+	runWrapper := NewRunWrapper("A", func(ctx context.Context,
+		ready chan<- struct{}, _, stopError chan<- error) {
+		path, _ := pprof.Label(ctx, ProfilerLabelService)
+		phase, _ := pprof.Label(ctx, ProfilerLabelPhase)
+		labels <- [2]string{path, phase}
+		close(ready)
+		<-ctx.Done()
+		close(stopError)
+	})
+
+	group, err := NewGroup(GroupSettings{
+		Name:     "g",
+		Services: []Service{runWrapper},
+	})
+	require.NoError(t, err)
+
+	sequence, err := NewSequence(SequenceSettings{
+		Name:           "s",
+		ServicesStart:  []Service{group},
+		ServicesStop:   []Service{group},
+		ProfilerLabels: true,
+	})
+	require.NoError(t, err)
+
+	_, err = sequence.Start(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, [2]string{"sequence s/group g/A", phaseRun}, <-labels)
+
+	err = sequence.Stop()
+	require.NoError(t, err)
+}
@@ -0,0 +1,127 @@
+package goservices
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Readier is an optional interface a service can implement to
+// report its readiness to serve, which can happen some time after
+// it started, for example once its caches are warm or once its
+// upstreams are connected. Services not implementing it are
+// considered ready as soon as they are started.
+type Readier interface {
+	// Ready returns a nil error if the service is ready, and
+	// an error describing why it is not ready otherwise.
+	// It must not block.
+	Ready() error
+}
+
+// forwarder is embedded in wrappers of a single service,
+// to forward optional interfaces to the service wrapped.
+type forwarder struct {
+	service Service
+}
+
+// Ready returns the readiness error of the wrapped service
+// if it implements `Readier`, and nil otherwise.
+func (f forwarder) Ready() (err error) {
+	return serviceReady(f.service)
+}
+
+// Forwarder can be embedded in wrappers of a single service defined
+// outside this package, to forward optional interfaces such as `Readier`
+// and `Drainer` to the service wrapped, as wrappers of this package do.
+type Forwarder struct {
+	forwarder
+}
+
+// NewForwarder returns a forwarder for the service given.
+func NewForwarder(service Service) Forwarder {
+	return Forwarder{forwarder: forwarder{service: service}}
+}
+
+// serviceReady returns the readiness error of the service
+// if it implements `Readier`, and nil otherwise.
+func serviceReady(service Service) (err error) {
+	readier, ok := service.(Readier)
+	if !ok {
+		return nil
+	}
+	return readier.Ready()
+}
+
+// servicesReady returns an error aggregating readiness errors of
+// the services given, or nil if all the services are ready.
+func servicesReady(services []Service) (err error) {
+	var errs readinessErrors
+	for _, service := range services {
+		err = serviceReady(service)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", service, err))
+		}
+	}
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return fmt.Errorf("%w: %w", ErrNotReady, errs[0])
+	default:
+		return fmt.Errorf("%w: %w", ErrNotReady, errs)
+	}
+}
+
+// readinessErrors aggregates readiness errors of multiple services.
+type readinessErrors []error
+
+func (r readinessErrors) Error() string {
+	messages := make([]string, len(r))
+	for i, err := range r {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (r readinessErrors) Unwrap() []error {
+	return r
+}
+
+// readinessPollPeriod is the period at which the readiness
+// of a service is polled when waiting for it to be ready.
+const readinessPollPeriod = 10 * time.Millisecond
+
+// waitReady waits for the service to be ready if it implements
+// `Readier`, for at most the timeout given. It returns early if
+// the context is canceled or if a crash error is received.
+func waitReady(ctx context.Context, service Service, timeout time.Duration,
+	crashed <-chan *CrashError) (crashErr *CrashError, err error) {
+	readier, ok := service.(Readier)
+	if !ok {
+		return nil, nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(readinessPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		err = readier.Ready()
+		if err == nil {
+			return nil, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return nil, fmt.Errorf("%w after %s: %w", ErrNotReady, timeout, err)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for readiness: %w: %w", ctx.Err(), err)
+		case crashErr = <-crashed:
+			return crashErr, nil
+		}
+	}
+}
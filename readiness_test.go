@@ -0,0 +1,151 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Group_Ready(t *testing.T) {
+	t.Parallel()
+
+	errWarming := errors.New("warming cache")
+	serviceA := newTestOptionalService("A")
+	serviceA.setNotReady(errWarming)
+	serviceB := newTestOptionalService("B")
+	serviceB.setNotReady(errors.New("connecting"))
+	serviceC := newTestService("C", nil, nil)
+
+	group, err := NewGroup(GroupSettings{
+		Services: []Service{serviceA, serviceB, serviceC},
+	})
+	require.NoError(t, err)
+
+	err = group.Ready()
+	assert.ErrorIs(t, err, ErrNotReady)
+	assert.EqualError(t, err, "not ready: group is stopped")
+
+	_, err = group.Start(context.Background())
+	require.NoError(t, err)
+
+	err = group.Ready()
+	assert.ErrorIs(t, err, errWarming)
+	assert.EqualError(t, err, "not ready: A: warming cache; B: connecting")
+
+	serviceB.setNotReady(nil)
+	err = group.Ready()
+	assert.EqualError(t, err, "not ready: A: warming cache")
+
+	serviceA.setNotReady(nil)
+	err = group.Ready()
+	assert.NoError(t, err)
+
+	err = group.Stop()
+	require.NoError(t, err)
+}
+
+func Test_Restarter_Ready(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	service := NewMockService(ctrl)
+	service.EXPECT().String().Return("A").AnyTimes()
+	firstRunError := make(chan error)
+	restarting := make(chan struct{})
+	resumeRestart := make(chan struct{})
+	gomock.InOrder(
+		service.EXPECT().Start(gomock.Any()).Return(firstRunError, nil),
+		service.EXPECT().Start(gomock.Any()).DoAndReturn(
+			func(context.Context) (<-chan error, error) {
+				close(restarting)
+				<-resumeRestart
+				return make(chan error), nil
+			}),
+		service.EXPECT().Stop().Return(nil),
+	)
+
+	restarter, err := NewRestarter(RestarterSettings{Service: service})
+	require.NoError(t, err)
+
+	err = restarter.Ready()
+	assert.ErrorIs(t, err, ErrNotReady)
+	assert.EqualError(t, err, "not ready: A is not running")
+
+	_, err = restarter.Start(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, restarter.Ready())
+
+	firstRunError <- errors.New("test crash")
+	<-restarting
+	err = restarter.Ready()
+	assert.EqualError(t, err, "not ready: A is not running")
+
+	close(resumeRestart)
+	assert.Eventually(t, func() bool {
+		return restarter.Ready() == nil
+	}, time.Second, time.Millisecond)
+
+	err = restarter.Stop()
+	require.NoError(t, err)
+}
+
+func Test_Sequence_ReadinessTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("wait_for_ready", func(t *testing.T) {
+		t.Parallel()
+
+		serviceA := newTestOptionalService("A")
+		serviceA.setNotReady(errors.New("warming cache"))
+		var bStartedBeforeAReady atomic.Bool
+		serviceB := newTestService("B", nil, func(context.Context) {
+			bStartedBeforeAReady.Store(serviceA.Ready() != nil)
+		})
+
+		sequence, err := NewSequence(SequenceSettings{
+			ServicesStart:    []Service{serviceA, serviceB},
+			ServicesStop:     []Service{serviceB, serviceA},
+			ReadinessTimeout: time.Second,
+		})
+		require.NoError(t, err)
+
+		time.AfterFunc(20*time.Millisecond, func() { serviceA.setNotReady(nil) })
+
+		_, err = sequence.Start(context.Background())
+		require.NoError(t, err)
+		assert.False(t, bStartedBeforeAReady.Load())
+		assert.NoError(t, sequence.Ready())
+
+		err = sequence.Stop()
+		require.NoError(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		serviceA := newTestOptionalService("A")
+		serviceA.setNotReady(errors.New("warming cache"))
+		serviceB := newTestOptionalService("B")
+
+		sequence, err := NewSequence(SequenceSettings{
+			ServicesStart:    []Service{serviceA, serviceB},
+			ServicesStop:     []Service{serviceB, serviceA},
+			ReadinessTimeout: 20 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		runError, err := sequence.Start(context.Background())
+		assert.Nil(t, runError)
+		assert.ErrorIs(t, err, ErrNotReady)
+		assert.EqualError(t, err, "starting A: not ready after 20ms: warming cache")
+
+		err = serviceA.Stop()
+		assert.ErrorIs(t, err, ErrAlreadyStopped)
+	})
+}
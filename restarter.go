@@ -2,8 +2,10 @@ package goservices
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 var _ Service = (*Restarter)(nil)
@@ -11,15 +13,24 @@ var _ Service = (*Restarter)(nil)
 // Restarter implements a service which restarts an
 // underlying service if it crashes. The restarter
 // only crashes if the underlying services fails to
-// start on a subsequent run.
+// start on a subsequent run, or if it crashes with an
+// error which should not be restarted, see `Permanent`.
 type Restarter struct {
-	service        Service
+	forwarder
 	hooks          Hooks
+	onlyRetryable  bool
+	profilerLabels bool
+	profiler       profiler
+	crashReporter  *crashReporter
+	restarts       uint
 	startStopMutex sync.Mutex
 	state          State
 	stateMutex     sync.RWMutex
-	interceptStop  chan struct{}
-	interceptDone  chan struct{}
+	// running is false whilst the underlying service is restarting,
+	// during which the state mutex is held, so `Ready` does not block.
+	running       atomic.Bool
+	interceptStop chan struct{}
+	interceptDone chan struct{}
 }
 
 // NewRestarter creates a new restarter given the settings.
@@ -33,12 +44,32 @@ func NewRestarter(settings RestarterSettings) (restarter *Restarter, err error)
 	}
 
 	return &Restarter{
-		service: settings.Service,
-		hooks:   settings.Hooks,
-		state:   StateStopped,
+		forwarder:      forwarder{service: settings.Service},
+		hooks:          settings.Hooks,
+		onlyRetryable:  settings.RestartOnlyRetryable,
+		profilerLabels: settings.ProfilerLabels,
+		crashReporter:  newCrashReporter(settings.CrashReports),
+		state:          StateStopped,
 	}, nil
 }
 
+// CrashReports returns the crash reports captured, from the
+// oldest to the most recent one, if crash reports are enabled.
+func (r *Restarter) CrashReports() (reports []CrashReport) {
+	return r.crashReporter.list()
+}
+
+// Ready returns an error wrapping `ErrNotReady` if the restarter is
+// not running, including whilst it restarts the underlying service
+// after a crash, and the readiness error of the underlying service
+// if it implements `Readier` otherwise.
+func (r *Restarter) Ready() (err error) {
+	if !r.running.Load() {
+		return fmt.Errorf("%w: %s is not running", ErrNotReady, r)
+	}
+	return r.forwarder.Ready()
+}
+
 func (r *Restarter) String() string {
 	return r.service.String()
 }
@@ -53,6 +84,10 @@ func (r *Restarter) String() string {
 //
 // If a subsequent service start fails, the start error is sent in the
 // `runError` channel, this channel is closed and the restarter stops.
+// Similarly, if the underlying service crashes with a permanent error
+// marked with `Permanent`, or with an error not marked with `Retryable`
+// when restarts are restricted to retryable errors, the crash error is
+// sent in the `runError` channel without restarting the service.
 // A caller should listen on `runError` until the `Stop` method
 // call fully completes, since a run error can theoretically happen
 // at the same time the caller calls `Stop` on the restarter.
@@ -80,16 +115,25 @@ func (r *Restarter) Start(ctx context.Context) (runError <-chan error, startErr
 	r.state = StateStarting
 
 	serviceString := r.service.String()
+	// The restarter is transparent and uses the underlying service
+	// name, so the underlying service path is the restarter path.
+	r.profiler = newProfiler(ctx, r.profilerLabels, serviceString)
 
-	r.hooks.OnStart(serviceString)
-	serviceRunError, startErr := r.service.Start(ctx)
-	r.hooks.OnStarted(serviceString, startErr)
+	var serviceRunError <-chan error
+	r.profiler.do(ctx, r.profiler.path, phaseStart, func(ctx context.Context) {
+		r.hooks.OnStart(serviceString)
+		serviceRunError, startErr = r.service.Start(ctx)
+		r.hooks.OnStarted(serviceString, startErr)
+	})
 
 	if startErr != nil {
 		startErr = addCtxErrorIfNeeded(startErr, ctx.Err())
 		return nil, startErr
 	}
 
+	r.restarts = 0
+	r.crashReporter.started(serviceString)
+
 	// Hold the state mutex until the intercept run error goroutine is ready
 	// and we change the state to running.
 	// This is as such because the intercept goroutine may catch a service run error
@@ -107,6 +151,7 @@ func (r *Restarter) Start(ctx context.Context) (runError <-chan error, startErr
 	<-interceptReady
 
 	r.state = StateRunning
+	r.running.Store(true)
 	r.stateMutex.Unlock()
 
 	return runErrorCh, nil
@@ -115,6 +160,7 @@ func (r *Restarter) Start(ctx context.Context) (runError <-chan error, startErr
 func (r *Restarter) interceptRunError(ready chan<- struct{},
 	serviceName string, input <-chan error, output chan<- error) {
 	defer close(r.interceptDone)
+	r.profiler.labelGoroutine(phaseRun)
 	close(ready)
 
 	for {
@@ -131,10 +177,20 @@ func (r *Restarter) interceptRunError(ready chan<- struct{},
 				r.stateMutex.Unlock()
 				return
 			}
+			r.running.Store(false)
 
 			r.hooks.OnCrash(serviceName, err)
+			r.crashReporter.report(r.hooks, r.profiler.path, serviceName, err, r.restarts)
 
-			r.hooks.OnStart(serviceName)
+			if !r.shouldRestart(err) {
+				// Escalate the crash error as is, since the
+				// restarter is transparent.
+				r.state = StateCrashed
+				r.stateMutex.Unlock()
+				output <- err
+				close(output)
+				return
+			}
 
 			// When an error is received from the input channel and
 			// the restarter is not stopping yet, the state mutex is
@@ -143,8 +199,12 @@ func (r *Restarter) interceptRunError(ready chan<- struct{},
 			// below. Therefore, it is fine to set the service start
 			// context as context.Background() and not cancel it.
 			var startErr error
-			input, startErr = r.service.Start(context.Background())
-			r.hooks.OnStarted(serviceName, startErr)
+			r.profiler.do(context.Background(), r.profiler.path, phaseStart,
+				func(ctx context.Context) {
+					r.hooks.OnStart(serviceName)
+					input, startErr = r.service.Start(ctx)
+					r.hooks.OnStarted(serviceName, startErr)
+				})
 
 			if startErr != nil {
 				r.state = StateCrashed
@@ -153,12 +213,28 @@ func (r *Restarter) interceptRunError(ready chan<- struct{},
 				close(output)
 				return
 			}
+			r.restarts++
+			r.crashReporter.started(serviceName)
 			r.state = StateRunning
+			r.running.Store(true)
 			r.stateMutex.Unlock()
 		}
 	}
 }
 
+// shouldRestart returns true if the service crashing with
+// the error given should be restarted.
+func (r *Restarter) shouldRestart(crashErr error) bool {
+	switch {
+	case errors.Is(crashErr, ErrPermanent):
+		return false
+	case r.onlyRetryable:
+		return errors.Is(crashErr, ErrRetryable)
+	default:
+		return true
+	}
+}
+
 // Stop stops the underlying service and the internal
 // run error restart-watcher goroutine.
 // If the restarter is already stopped, the `ErrAlreadyStopped` error
@@ -173,8 +249,9 @@ func (r *Restarter) Stop() (err error) {
 	switch r.state {
 	case StateRunning: // continue stopping the restarter
 	case StateCrashed:
-		// service crashed and failed to restart, just wait
-		// for the intercept goroutine to finish.
+		r.stateMutex.Unlock()
+		// service crashed and failed to restart or was not
+		// restarted, just wait for the intercept goroutine to finish.
 		<-r.interceptDone
 		return nil
 	case StateStopped:
@@ -185,13 +262,17 @@ func (r *Restarter) Stop() (err error) {
 		panic("bad restarter implementation code: this code path should be unreachable")
 	}
 	r.state = StateStopping
+	r.running.Store(false)
 	r.stateMutex.Unlock()
 
 	serviceString := r.service.String()
 
-	r.hooks.OnStop(serviceString)
-	err = r.service.Stop()
-	r.hooks.OnStopped(serviceString, err)
+	r.profiler.do(context.Background(), r.profiler.path, phaseStop,
+		func(context.Context) {
+			r.hooks.OnStop(serviceString)
+			err = r.service.Stop()
+			r.hooks.OnStopped(serviceString, err)
+		})
 
 	// Stop the intercept error goroutine after we stop
 	// the restarter underlying service.
@@ -15,6 +15,28 @@ type RestarterSettings struct {
 	// stops or crashes. It defaults to a noop hooks
 	// implementation.
 	Hooks Hooks
+	// RestartOnlyRetryable, if set to true, restricts restarts to
+	// services crashing with an error marked as retryable with
+	// `Retryable`. Other crash errors are forwarded in the restarter
+	// run error channel. Regardless of this setting, services crashing
+	// with an error marked as permanent with `Permanent` are never
+	// restarted. It defaults to false.
+	RestartOnlyRetryable bool
+	// ProfilerLabels enables setting pprof goroutine labels
+	// and runtime/trace tasks and regions for the start and stop
+	// of the service, so profiles and execution traces can be
+	// attributed to the service. See `ProfilerLabelService`
+	// and `ProfilerLabelPhase` for the label keys used.
+	// Note it is automatically enabled if the restarter is started by
+	// a parent service management type with profiler labels enabled.
+	ProfilerLabels bool
+	// CrashReports, if set, enables capturing a crash report
+	// when a service crashes. Crash reports are retrievable with
+	// the `CrashReports` method, are passed to the hooks if they
+	// implement `HooksCrashReport` and can be written as JSON files
+	// to a crash directory. It defaults to nil, meaning crash
+	// reports are disabled.
+	CrashReports *CrashReportSettings
 }
 
 // setDefaults sets the defaults for the restarter settings.
@@ -22,6 +44,12 @@ func (r *RestarterSettings) setDefaults() {
 	if r.Hooks == nil {
 		r.Hooks = hooks.NewNoop()
 	}
+
+	if r.CrashReports != nil {
+		crashReports := *r.CrashReports
+		crashReports.setDefaults()
+		r.CrashReports = &crashReports
+	}
 }
 
 // validate validates the restarter settings.
@@ -34,8 +34,8 @@ func Test_NewRestarter(t *testing.T) {
 				Service: dummyService,
 			},
 			restarter: &Restarter{
-				service: dummyService,
-				hooks:   hooks.NewNoop(),
+				forwarder: forwarder{service: dummyService},
+				hooks:     hooks.NewNoop(),
 			},
 		},
 	}
@@ -63,7 +63,7 @@ func Test_Restarter_String(t *testing.T) {
 	service.EXPECT().String().Return("A")
 
 	restarter := &Restarter{
-		service: service,
+		forwarder: forwarder{service: service},
 	}
 
 	s := restarter.String()
@@ -83,8 +83,8 @@ func Test_Restarter_Start(t *testing.T) {
 		service.EXPECT().String().Return("A")
 
 		restarter := &Restarter{
-			service: service,
-			state:   StateRunning,
+			forwarder: forwarder{service: service},
+			state:     StateRunning,
 		}
 
 		_, err := restarter.Start(ctx)
@@ -283,7 +283,7 @@ func Test_Restarter_interceptRunError(t *testing.T) {
 		hooks := NewMockHooks(ctrl)
 
 		restarter := Restarter{
-			service:       service,
+			forwarder:     forwarder{service: service},
 			hooks:         hooks,
 			interceptStop: make(chan struct{}),
 			interceptDone: make(chan struct{}),
@@ -317,7 +317,7 @@ func Test_Restarter_interceptRunError(t *testing.T) {
 		hooks := NewMockHooks(ctrl)
 
 		restarter := Restarter{
-			service:       service,
+			forwarder:     forwarder{service: service},
 			hooks:         hooks,
 			interceptStop: make(chan struct{}),
 			interceptDone: make(chan struct{}),
@@ -369,7 +369,7 @@ func Test_Restarter_Stop(t *testing.T) {
 		service.EXPECT().String().Return("A")
 
 		restarter := Restarter{
-			service: service,
+			forwarder: forwarder{service: service},
 		}
 
 		err := restarter.Stop()
@@ -403,7 +403,7 @@ func Test_Restarter_Stop(t *testing.T) {
 		hooks.EXPECT().OnStopped("A", errTest)
 
 		restarter := Restarter{
-			service:       service,
+			forwarder:     forwarder{service: service},
 			state:         StateRunning,
 			hooks:         hooks,
 			interceptStop: make(chan struct{}),
@@ -0,0 +1,59 @@
+package goservices
+
+import (
+	"context"
+)
+
+// Run starts the service given, blocks until the service crashes
+// or the context is canceled, in which case it stops the service.
+// It listens on the service run error channel while the service is
+// stopping, as required by the service management types.
+//
+// It returns:
+//   - a `*StartError` if the service failed to start
+//   - a `*CrashError` if the service crashed
+//   - a `*StopError` if the service failed to stop
+//   - nil if the service was stopped successfully, or if it exited
+//     by closing its run error channel without sending an error
+//
+// Note if the context is canceled whilst the service is starting,
+// the returned `*StartError` wraps the context error.
+func Run(ctx context.Context, service Service) (err error) {
+	serviceString := service.String()
+
+	runError, err := service.Start(ctx)
+	if err != nil {
+		return &StartError{Service: serviceString, Err: err}
+	}
+
+	select {
+	case runErr, ok := <-runError:
+		if !ok {
+			return nil
+		}
+		return &CrashError{Service: serviceString, Err: runErr}
+	case <-ctx.Done():
+	}
+
+	stopErrCh := make(chan error)
+	go func() {
+		stopErrCh <- service.Stop()
+	}()
+
+	for {
+		select {
+		case err = <-stopErrCh:
+			if err != nil {
+				return &StopError{Service: serviceString, Err: err}
+			}
+			return nil
+		case _, ok := <-runError:
+			// Discard a run error happening concurrently with the
+			// stop operation, which should be reported by the stop
+			// error if relevant.
+			if !ok {
+				runError = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Run(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	t.Run("start error", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		service := NewMockService(ctrl)
+		service.EXPECT().String().Return("A")
+		service.EXPECT().Start(ctx).Return(nil, errTest)
+
+		err := Run(ctx, service)
+
+		var startErr *StartError
+		assert.ErrorAs(t, err, &startErr)
+		assert.ErrorIs(t, err, errTest)
+		assert.EqualError(t, err, "starting A: test error")
+	})
+
+	t.Run("crash", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		service := NewMockService(ctrl)
+		service.EXPECT().String().Return("A")
+		runError := make(chan error, 1)
+		runError <- errTest
+		service.EXPECT().Start(ctx).Return(runError, nil)
+
+		err := Run(ctx, service)
+
+		var crashErr *CrashError
+		assert.ErrorAs(t, err, &crashErr)
+		assert.ErrorIs(t, err, errTest)
+		assert.EqualError(t, err, "A crashed: test error")
+	})
+
+	t.Run("run error channel closed", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		ctx := context.Background()
+
+		service := NewMockService(ctrl)
+		service.EXPECT().String().Return("A")
+		runError := make(chan error)
+		close(runError)
+		service.EXPECT().Start(ctx).Return(runError, nil)
+
+		err := Run(ctx, service)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("stop error with run error whilst stopping", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		service := NewMockService(ctrl)
+		service.EXPECT().String().Return("A")
+		runError := make(chan error)
+		service.EXPECT().Start(ctx).Return(runError, nil)
+		service.EXPECT().Stop().DoAndReturn(func() error {
+			// unbuffered so Run must be listening on it
+			runError <- errTest
+			close(runError)
+			return errTest
+		})
+
+		err := Run(ctx, service)
+
+		var stopErr *StopError
+		assert.ErrorAs(t, err, &stopErr)
+		assert.EqualError(t, err, "stopping A: test error")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		ctrl := gomock.NewController(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		service := NewMockService(ctrl)
+		service.EXPECT().String().Return("A")
+		service.EXPECT().Start(ctx).Return(make(chan error), nil)
+		service.EXPECT().Stop().Return(nil)
+
+		err := Run(ctx, service)
+
+		assert.NoError(t, err)
+	})
+}
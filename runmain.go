@@ -0,0 +1,107 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// Main runs the root service given in the settings until it crashes
+// or one of the settings signals is received, in which case it stops
+// the service. It is meant to be used in a program main function as
+// `os.Exit(goservices.Main(settings))`.
+//
+// If a second signal is received whilst the service is stopping,
+// or if the service does not stop within the shutdown timeout,
+// Main returns immediately without waiting for the service to stop.
+//
+// The exit code returned is zero on success, and otherwise one of
+// the exit codes from the settings, depending on whether the settings
+// are invalid, the service failed to start, crashed, failed to stop,
+// did not stop in time or if the exit was forced by a second signal.
+// Note a signal received whilst the service is starting cancels the
+// start, and is considered a success if all started services stop.
+// A crash wrapping `ErrSignalReceived` or `ErrUpgraded` is also
+// considered a success.
+func Main(settings MainSettings) (exitCode int) {
+	settings.setDefaults()
+	err := settings.validate()
+	if err != nil {
+		settings.Logger.Error("validating settings: " + err.Error())
+		return settings.ExitCodes.Settings
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, settings.Signals...)
+	defer signal.Stop(signals)
+
+	return runMain(settings, signals)
+}
+
+func runMain(settings MainSettings, signals <-chan os.Signal) (exitCode int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runDone := make(chan error, 1)
+	go func() {
+		runDone <- Run(ctx, settings.Service)
+	}()
+
+	select {
+	case err := <-runDone:
+		return errorToExitCode(err, settings.ExitCodes, settings.Logger, false)
+	case receivedSignal := <-signals:
+		settings.Logger.Info(fmt.Sprintf("%s received, stopping %s "+
+			"(send it again to force exit)", receivedSignal, settings.Service))
+		cancel()
+	}
+
+	var timeout <-chan time.Time
+	if settings.ShutdownTimeout > 0 {
+		timer := time.NewTimer(settings.ShutdownTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case err := <-runDone:
+		return errorToExitCode(err, settings.ExitCodes, settings.Logger, true)
+	case receivedSignal := <-signals:
+		settings.Logger.Error(fmt.Sprintf("%s received again, forcing exit", receivedSignal))
+		return settings.ExitCodes.Forced
+	case <-timeout:
+		settings.Logger.Error(fmt.Sprintf("%s did not stop within %s",
+			settings.Service, settings.ShutdownTimeout))
+		return settings.ExitCodes.ShutdownTimeout
+	}
+}
+
+func errorToExitCode(err error, exitCodes ExitCodes,
+	logger MainLogger, signaled bool) (exitCode int) {
+	if err == nil {
+		return 0
+	}
+
+	switch err.(type) { //nolint:errorlint
+	case *StartError:
+		if signaled && errors.Is(err, context.Canceled) {
+			return 0
+		}
+		exitCode = exitCodes.Start
+	case *CrashError:
+		if errors.Is(err, ErrSignalReceived) || errors.Is(err, ErrUpgraded) {
+			logger.Info(err.Error())
+			return 0
+		}
+		exitCode = exitCodes.Crash
+	case *StopError:
+		exitCode = exitCodes.Stop
+	default:
+		panic(fmt.Sprintf("unexpected error type %T: %s", err, err))
+	}
+	logger.Error(err.Error())
+	return exitCode
+}
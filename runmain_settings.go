@@ -0,0 +1,117 @@
+package goservices
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+)
+
+// MainSettings contains settings for the `Main` helper.
+type MainSettings struct {
+	// Service is the root service to run.
+	// It must be set for settings validation to succeed.
+	Service Service
+	// Signals are the signals triggering the service to stop.
+	// A second signal received whilst stopping forces an exit.
+	// It defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+	// ShutdownTimeout is the maximum duration to wait for the
+	// service to stop once a signal is received. It defaults to
+	// 10 seconds, and can be set to a negative value for no timeout.
+	ShutdownTimeout time.Duration
+	// ExitCodes are the process exit codes to return.
+	ExitCodes ExitCodes
+	// Logger is used to log signals received and errors.
+	// It defaults to a logger writing to the standard error.
+	Logger MainLogger
+}
+
+// ExitCodes contains process exit codes returned by `Main`.
+// Note a zero exit code is always returned on success.
+type ExitCodes struct {
+	// Settings is the exit code for invalid settings.
+	// It defaults to 1.
+	Settings int
+	// Start is the exit code for a service start error.
+	// It defaults to 2.
+	Start int
+	// Crash is the exit code for a service crash.
+	// It defaults to 3.
+	Crash int
+	// Stop is the exit code for a service stop error.
+	// It defaults to 4.
+	Stop int
+	// ShutdownTimeout is the exit code when the service does not
+	// stop within the shutdown timeout. It defaults to 5.
+	ShutdownTimeout int
+	// Forced is the exit code when a second signal is
+	// received whilst stopping. It defaults to 130.
+	Forced int
+}
+
+// MainLogger is the logger interface required by `Main`.
+type MainLogger interface {
+	Info(message string)
+	Error(message string)
+}
+
+// setDefaults sets the defaults for the main settings.
+func (m *MainSettings) setDefaults() {
+	if m.Signals == nil {
+		m.Signals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	if m.ShutdownTimeout == 0 {
+		const defaultShutdownTimeout = 10 * time.Second
+		m.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	m.ExitCodes.setDefaults()
+
+	if m.Logger == nil {
+		m.Logger = &stderrLogger{}
+	}
+}
+
+func (e *ExitCodes) setDefaults() {
+	const (
+		defaultSettings        = 1
+		defaultStart           = 2
+		defaultCrash           = 3
+		defaultStop            = 4
+		defaultShutdownTimeout = 5
+		defaultForced          = 130 // 128 + SIGINT
+	)
+	for _, field := range []struct {
+		value        *int
+		defaultValue int
+	}{
+		{value: &e.Settings, defaultValue: defaultSettings},
+		{value: &e.Start, defaultValue: defaultStart},
+		{value: &e.Crash, defaultValue: defaultCrash},
+		{value: &e.Stop, defaultValue: defaultStop},
+		{value: &e.ShutdownTimeout, defaultValue: defaultShutdownTimeout},
+		{value: &e.Forced, defaultValue: defaultForced},
+	} {
+		if *field.value == 0 {
+			*field.value = field.defaultValue
+		}
+	}
+}
+
+// validate validates the main settings.
+func (m MainSettings) validate() (err error) {
+	switch {
+	case m.Service == nil:
+		return fmt.Errorf("%w", ErrNoService)
+	case len(m.Signals) == 0:
+		return fmt.Errorf("%w", ErrNoSignal)
+	}
+	return nil
+}
+
+type stderrLogger struct{}
+
+func (stderrLogger) Info(message string)  { fmt.Fprintln(os.Stderr, message) }
+func (stderrLogger) Error(message string) { fmt.Fprintln(os.Stderr, "ERROR "+message) }
@@ -0,0 +1,171 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMainLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *testMainLogger) Info(message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, "INFO "+message)
+}
+
+func (l *testMainLogger) Error(message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, "ERROR "+message)
+}
+
+func Test_Main_invalidSettings(t *testing.T) {
+	t.Parallel()
+
+	logger := &testMainLogger{}
+
+	exitCode := Main(MainSettings{Logger: logger})
+
+	assert.Equal(t, 1, exitCode)
+	assert.Equal(t, []string{"ERROR validating settings: no service specified"}, logger.messages)
+}
+
+func Test_runMain(t *testing.T) {
+	t.Parallel()
+
+	errTest := errors.New("test error")
+
+	newService := func(stopDuration time.Duration, crash <-chan struct{},
+		stopErr error) *RunWrapper {
+		return NewRunWrapper("A", func(ctx context.Context,
+			ready chan<- struct{}, runError, stopError chan<- error) {
+			close(ready)
+			select {
+			case <-ctx.Done():
+				time.Sleep(stopDuration)
+				stopError <- stopErr
+				close(stopError)
+			case <-crash:
+				runError <- errTest
+				close(runError)
+			}
+		})
+	}
+
+	testCases := map[string]struct {
+		stopDuration time.Duration
+		crash        bool
+		stopErr      error
+		signals      int
+		exitCode     int
+		messages     []string
+	}{
+		"crash": {
+			crash:    true,
+			exitCode: 3,
+			messages: []string{"ERROR A crashed: test error"},
+		},
+		"signal and successful stop": {
+			signals:  1,
+			messages: []string{"INFO terminated received, stopping A (send it again to force exit)"},
+		},
+		"signal and stop error": {
+			signals:  1,
+			stopErr:  errTest,
+			exitCode: 4,
+			messages: []string{
+				"INFO terminated received, stopping A (send it again to force exit)",
+				"ERROR stopping A: test error",
+			},
+		},
+		"signal and shutdown timeout": {
+			signals:      1,
+			stopDuration: time.Second,
+			exitCode:     5,
+			messages: []string{
+				"INFO terminated received, stopping A (send it again to force exit)",
+				"ERROR A did not stop within 10ms",
+			},
+		},
+		"second signal": {
+			signals:      2,
+			stopDuration: time.Second,
+			exitCode:     130,
+			messages: []string{
+				"INFO terminated received, stopping A (send it again to force exit)",
+				"ERROR terminated received again, forcing exit",
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			crash := make(chan struct{})
+			if testCase.crash {
+				close(crash)
+			}
+			logger := &testMainLogger{}
+			settings := MainSettings{
+				Service: newService(testCase.stopDuration, crash, testCase.stopErr),
+				Logger:  logger,
+			}
+			settings.setDefaults()
+			if testCase.stopDuration > 0 && testCase.signals == 1 {
+				settings.ShutdownTimeout = 10 * time.Millisecond
+			}
+
+			signals := make(chan os.Signal, testCase.signals)
+			for range testCase.signals {
+				signals <- syscall.SIGTERM
+			}
+
+			exitCode := runMain(settings, signals)
+
+			assert.Equal(t, testCase.exitCode, exitCode)
+			assert.Equal(t, testCase.messages, logger.messages)
+		})
+	}
+}
+
+func Test_errorToExitCode_signalReceived(t *testing.T) {
+	t.Parallel()
+
+	logger := &testMainLogger{}
+	err := &CrashError{
+		Service: "signals",
+		Err:     fmt.Errorf("%w: terminated", ErrSignalReceived),
+	}
+
+	exitCode := errorToExitCode(err, ExitCodes{Crash: 3}, logger, false)
+
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, []string{"INFO signals crashed: signal received: terminated"}, logger.messages)
+}
+
+func Test_errorToExitCode_upgraded(t *testing.T) {
+	t.Parallel()
+
+	logger := &testMainLogger{}
+	err := &CrashError{
+		Service: "upgrader",
+		Err:     fmt.Errorf("%w: process 2 is ready", ErrUpgraded),
+	}
+
+	exitCode := errorToExitCode(err, ExitCodes{Crash: 3}, logger, false)
+
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, []string{"INFO upgrader crashed: upgraded: process 2 is ready"}, logger.messages)
+}
@@ -3,6 +3,7 @@ package goservices
 import (
 	"context"
 	"fmt"
+	"runtime/pprof"
 	"sync"
 )
 
@@ -76,6 +77,11 @@ type RunWrapper struct {
 
 // NewRunWrapper creates a new service wrapper using the
 // service name and run function given.
+// If the wrapper is started by a service management type with
+// profiler labels enabled, the run function goroutine and its
+// context are labeled with its service path and the "run" phase.
+// If the wrapper is started by a `HeartbeatWatchdog`, the run function
+// can get its heartbeat function with `HeartbeatFromContext(ctx)`.
 func NewRunWrapper(name string, run RunFunction) *RunWrapper {
 	return &RunWrapper{
 		name:  name,
@@ -147,8 +153,28 @@ func (w *RunWrapper) Start(startCtx context.Context) (runError <-chan error, sta
 		}
 	}()
 
+	// Label the run goroutine if the start context has profiler
+	// labels set by a parent service management type. The labels
+	// are also set on the run context, so the run function can
+	// propagate them to goroutines it spawns using `pprof.Do`.
+	profiler := newProfiler(startCtx, false, w.name)
+	if profiler.enabled {
+		ctx = pprof.WithLabels(ctx, profilerLabels(profiler.path, phaseRun))
+	}
+
+	// Pass on the heartbeat function set by an eventual parent
+	// `HeartbeatWatchdog`, see `HeartbeatFromContext`.
+	if heartbeat, ok := startCtx.Value(heartbeatContextKey{}).(func()); ok {
+		ctx = contextWithHeartbeat(ctx, heartbeat)
+	}
+
 	runReady := make(chan struct{})
-	go w.run(ctx, runReady, runErrorToInject, stopError) //nolint:contextcheck
+	go func() {
+		if profiler.enabled {
+			pprof.SetGoroutineLabels(ctx)
+		}
+		w.run(ctx, runReady, runErrorToInject, stopError) //nolint:contextcheck
+	}()
 
 	// Check if there is a run error before the ready channel is closed.
 	// That would effectively represent a start error.
@@ -0,0 +1,60 @@
+package sdnotify
+
+import (
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Hooks = (*Hooks)(nil)
+
+// Hooks sends a `STATUS=` notification to systemd for each service
+// event, so `systemctl status` reflects the current state of the
+// service tree. It can be set as the hooks of the service management
+// types of the tree, such as `goservices.Group`.
+// Notification errors are ignored since they are best effort.
+type Hooks struct {
+	socket string
+}
+
+// NewHooks creates hooks notifying the systemd notification
+// socket given, which is typically `os.Getenv("NOTIFY_SOCKET")`.
+// Notifications are disabled if the socket is the empty string.
+func NewHooks(socket string) *Hooks {
+	return &Hooks{
+		socket: socket,
+	}
+}
+
+// OnStart sends a status notification of the service starting.
+func (h *Hooks) OnStart(service string) {
+	_ = notify(h.socket, status("starting %s", service))
+}
+
+// OnStarted sends a status notification of the service started,
+// or of its start error if it failed to start.
+func (h *Hooks) OnStarted(service string, err error) {
+	if err != nil {
+		_ = notify(h.socket, status("%s failed to start: %s", service, err))
+		return
+	}
+	_ = notify(h.socket, status("started %s", service))
+}
+
+// OnStop sends a status notification of the service stopping.
+func (h *Hooks) OnStop(service string) {
+	_ = notify(h.socket, status("stopping %s", service))
+}
+
+// OnStopped sends a status notification of the service stopped,
+// or of its stop error if it failed to stop.
+func (h *Hooks) OnStopped(service string, err error) {
+	if err != nil {
+		_ = notify(h.socket, status("%s failed to stop: %s", service, err))
+		return
+	}
+	_ = notify(h.socket, status("stopped %s", service))
+}
+
+// OnCrash sends a status notification of the service crash error.
+func (h *Hooks) OnCrash(service string, err error) {
+	_ = notify(h.socket, status("%s crashed: %s", service, err))
+}
@@ -0,0 +1,191 @@
+// Package sdnotify implements the systemd notification protocol
+// for a root service, for programs run as `Type=notify` systemd units.
+package sdnotify
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Service = (*Notifier)(nil)
+
+// Notifier wraps a root service and notifies systemd of its state.
+// It sends `READY=1` once the service started, `STOPPING=1` when
+// the service starts stopping, `STATUS=` lines reflecting the state
+// of the service, and `WATCHDOG=1` pings only whilst the service is
+// running and has not crashed.
+type Notifier struct {
+	service          goservices.Service
+	socket           string
+	watchdogInterval time.Duration
+	startStopMutex   sync.Mutex
+	state            goservices.State
+	stateMutex       sync.RWMutex
+	interceptStop    chan struct{}
+	interceptDone    chan struct{}
+}
+
+// New creates a new systemd notifier wrapping the service given
+// in the settings. It returns an error if the settings are not valid.
+func New(settings Settings) (notifier *Notifier, err error) {
+	if settings.WatchdogInterval == nil {
+		interval, err := watchdogIntervalFromEnv()
+		if err != nil {
+			return nil, fmt.Errorf("getting watchdog interval: %w", err)
+		}
+		settings.WatchdogInterval = &interval
+	}
+
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &Notifier{
+		service:          settings.Service,
+		socket:           *settings.Socket,
+		watchdogInterval: *settings.WatchdogInterval,
+		state:            goservices.StateStopped,
+	}, nil
+}
+
+func (n *Notifier) String() string {
+	return n.service.String()
+}
+
+// Start starts the underlying service and sends `READY=1` once
+// it started successfully. If the readiness notification fails,
+// the underlying service is stopped and an error is returned.
+func (n *Notifier) Start(ctx context.Context) (runError <-chan error, startErr error) {
+	n.startStopMutex.Lock()
+	defer n.startStopMutex.Unlock()
+
+	n.stateMutex.RLock()
+	state := n.state
+	n.stateMutex.RUnlock()
+	if state == goservices.StateRunning {
+		return nil, fmt.Errorf("%s: %w", n, goservices.ErrAlreadyStarted)
+	}
+
+	n.state = goservices.StateStarting
+	_ = notify(n.socket, status("starting"))
+
+	serviceRunError, startErr := n.service.Start(ctx)
+	if startErr != nil {
+		n.state = goservices.StateStopped
+		_ = notify(n.socket, status("failed to start: %s", startErr))
+		return nil, startErr
+	}
+
+	err := notify(n.socket, "READY=1", status("running"))
+	if err != nil {
+		n.state = goservices.StateStopped
+		stopErr := n.service.Stop()
+		if stopErr != nil {
+			return nil, fmt.Errorf("notifying readiness: %w (and stopping: %w)", err, stopErr)
+		}
+		return nil, fmt.Errorf("notifying readiness: %w", err)
+	}
+
+	// Hold the state mutex until the intercept goroutine is ready,
+	// in case the service crashes as soon as the goroutine starts.
+	n.stateMutex.Lock()
+	interceptReady := make(chan struct{})
+	runErrorCh := make(chan error)
+	n.interceptStop = make(chan struct{})
+	n.interceptDone = make(chan struct{})
+	go n.interceptRunError(interceptReady, serviceRunError, runErrorCh)
+	<-interceptReady
+	n.state = goservices.StateRunning
+	n.stateMutex.Unlock()
+
+	return runErrorCh, nil
+}
+
+func (n *Notifier) interceptRunError(ready chan<- struct{},
+	input <-chan error, output chan<- error) {
+	defer close(n.interceptDone)
+
+	// A nil ticker channel blocks forever, disabling watchdog pings.
+	var watchdogTick <-chan time.Time
+	if n.watchdogInterval > 0 {
+		ticker := time.NewTicker(n.watchdogInterval)
+		defer ticker.Stop()
+		watchdogTick = ticker.C
+	}
+	close(ready)
+
+	for {
+		select {
+		case <-n.interceptStop:
+			return
+		case <-watchdogTick:
+			n.stateMutex.RLock()
+			if n.state == goservices.StateRunning {
+				_ = notify(n.socket, "WATCHDOG=1")
+			}
+			n.stateMutex.RUnlock()
+		case err := <-input:
+			n.stateMutex.Lock()
+			if n.state == goservices.StateStopping {
+				// Discard the run error if we are stopping.
+				n.stateMutex.Unlock()
+				return
+			}
+			n.state = goservices.StateCrashed
+			n.stateMutex.Unlock()
+			_ = notify(n.socket, status("crashed: %s", err))
+			output <- err
+			close(output)
+			return
+		}
+	}
+}
+
+// Stop sends `STOPPING=1` and stops the underlying service.
+// If the notifier is already stopped, the `ErrAlreadyStopped`
+// error is returned.
+func (n *Notifier) Stop() (err error) {
+	n.startStopMutex.Lock()
+	defer n.startStopMutex.Unlock()
+
+	n.stateMutex.Lock()
+	switch n.state {
+	case goservices.StateRunning: // continue stopping the notifier
+	case goservices.StateCrashed:
+		n.stateMutex.Unlock()
+		<-n.interceptDone
+		n.state = goservices.StateStopped
+		return nil
+	case goservices.StateStopped:
+		n.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", n, goservices.ErrAlreadyStopped)
+	case goservices.StateStarting, goservices.StateStopping:
+		n.stateMutex.Unlock()
+		panic("bad implementation code: this code path should be unreachable")
+	}
+	n.state = goservices.StateStopping
+	n.stateMutex.Unlock()
+
+	_ = notify(n.socket, "STOPPING=1", status("stopping"))
+
+	err = n.service.Stop()
+
+	// Stop the intercept goroutine after stopping the underlying
+	// service to drain an eventual run error.
+	close(n.interceptStop)
+	<-n.interceptDone
+
+	n.state = goservices.StateStopped
+	if err != nil {
+		_ = notify(n.socket, status("failed to stop: %s", err))
+		return err
+	}
+	_ = notify(n.socket, status("stopped"))
+	return nil
+}
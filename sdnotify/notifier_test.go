@@ -0,0 +1,213 @@
+package sdnotify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/qdm12/goservices/internal/servicetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenNotifySocket(t *testing.T) (socket string, messages <-chan string) {
+	t.Helper()
+
+	socket = filepath.Join(t.TempDir(), "notify.sock")
+	connection, err := net.ListenUnixgram("unixgram",
+		&net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = connection.Close() })
+
+	messagesCh := make(chan string, 100)
+	go func() {
+		buffer := make([]byte, 4096)
+		for {
+			n, err := connection.Read(buffer)
+			if err != nil {
+				close(messagesCh)
+				return
+			}
+			messagesCh <- string(buffer[:n])
+		}
+	}()
+	return socket, messagesCh
+}
+
+func receive(t *testing.T, messages <-chan string) string {
+	t.Helper()
+	select {
+	case message := <-messages:
+		return message
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for notification")
+		return ""
+	}
+}
+
+func Test_Notifier_startStop(t *testing.T) {
+	t.Parallel()
+
+	socket, messages := listenNotifySocket(t)
+
+	notifier, err := New(Settings{
+		Service:          servicetest.New("A", nil),
+		Socket:           &socket,
+		WatchdogInterval: new(time.Duration),
+	})
+	require.NoError(t, err)
+
+	_, err = notifier.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "STATUS=starting", receive(t, messages))
+	assert.Equal(t, "READY=1\nSTATUS=running", receive(t, messages))
+
+	err = notifier.Stop()
+	require.NoError(t, err)
+	assert.Equal(t, "STOPPING=1\nSTATUS=stopping", receive(t, messages))
+	assert.Equal(t, "STATUS=stopped", receive(t, messages))
+
+	err = notifier.Stop()
+	assert.ErrorIs(t, err, goservices.ErrAlreadyStopped)
+}
+
+func Test_Notifier_watchdog(t *testing.T) {
+	t.Parallel()
+
+	socket, messages := listenNotifySocket(t)
+	crash := make(chan error)
+	watchdogInterval := time.Millisecond
+
+	notifier, err := New(Settings{
+		Service:          servicetest.New("A", crash),
+		Socket:           &socket,
+		WatchdogInterval: &watchdogInterval,
+	})
+	require.NoError(t, err)
+
+	runError, err := notifier.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "STATUS=starting", receive(t, messages))
+	assert.Equal(t, "READY=1\nSTATUS=running", receive(t, messages))
+	assert.Equal(t, "WATCHDOG=1", receive(t, messages))
+
+	errTest := errors.New("test error")
+	crash <- errTest
+	err = <-runError
+	assert.ErrorIs(t, err, errTest)
+
+	// Skip pings sent before the crash
+	message := receive(t, messages)
+	for message == "WATCHDOG=1" {
+		message = receive(t, messages)
+	}
+	assert.Equal(t, "STATUS=crashed: test error", message)
+
+	// No more pings once crashed
+	select {
+	case message = <-messages:
+		t.Fatalf("unexpected notification after crash: %q", message)
+	case <-time.After(10 * watchdogInterval):
+	}
+
+	err = notifier.Stop()
+	assert.NoError(t, err)
+}
+
+func Test_Notifier_readyFailure(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "missing.sock")
+	notifier, err := New(Settings{
+		Service:          servicetest.New("A", nil),
+		Socket:           &socket,
+		WatchdogInterval: new(time.Duration),
+	})
+	require.NoError(t, err)
+
+	runError, err := notifier.Start(context.Background())
+	assert.Nil(t, runError)
+	assert.ErrorContains(t, err, "notifying readiness: dialing notify socket: ")
+
+	err = notifier.Stop()
+	assert.ErrorIs(t, err, goservices.ErrAlreadyStopped)
+}
+
+func Test_Hooks(t *testing.T) {
+	t.Parallel()
+
+	socket, messages := listenNotifySocket(t)
+	hooks := NewHooks(socket)
+
+	hooks.OnStart("A")
+	hooks.OnStarted("A", nil)
+	hooks.OnCrash("A", errors.New("multi\nline"))
+	hooks.OnStop("B")
+	hooks.OnStopped("B", errors.New("test"))
+
+	expectedMessages := []string{
+		"STATUS=starting A",
+		"STATUS=started A",
+		"STATUS=A crashed: multi line",
+		"STATUS=stopping B",
+		"STATUS=B failed to stop: test",
+	}
+	for _, expected := range expectedMessages {
+		assert.Equal(t, expected, receive(t, messages))
+	}
+}
+
+func Test_watchdogIntervalFromEnv(t *testing.T) { //nolint:paralleltest
+	testCases := map[string]struct {
+		usec       string
+		pid        string
+		interval   time.Duration
+		errWrapped error
+	}{
+		"unset": {},
+		"half_usec": {
+			usec:     "2000000",
+			interval: time.Second,
+		},
+		"matching_pid": {
+			usec:     "2000000",
+			pid:      "self",
+			interval: time.Second,
+		},
+		"other_pid": {
+			usec: "2000000",
+			pid:  "1",
+		},
+		"malformed_usec": {
+			usec:       "x",
+			errWrapped: ErrWatchdogUsecNotValid,
+		},
+		"malformed_pid": {
+			usec:       "2000000",
+			pid:        "x",
+			errWrapped: ErrWatchdogPIDNotValid,
+		},
+	}
+
+	for name, testCase := range testCases { //nolint:paralleltest
+		t.Run(name, func(t *testing.T) {
+			pid := testCase.pid
+			if pid == "self" {
+				pid = strconv.Itoa(os.Getpid())
+			}
+			t.Setenv("WATCHDOG_USEC", testCase.usec)
+			t.Setenv("WATCHDOG_PID", pid)
+
+			interval, err := watchdogIntervalFromEnv()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			assert.Equal(t, testCase.interval, interval)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package sdnotify
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// notify sends the state lines given as a single datagram to the
+// systemd notification socket. It is a no-op if the socket is empty.
+func notify(socket string, lines ...string) (err error) {
+	if socket == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	address := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	connection, err := net.DialUnix("unixgram", nil, address)
+	if err != nil {
+		return fmt.Errorf("dialing notify socket: %w", err)
+	}
+
+	message := strings.Join(lines, "\n")
+	_, err = connection.Write([]byte(message))
+	if err != nil {
+		_ = connection.Close()
+		return fmt.Errorf("writing to notify socket: %w", err)
+	}
+
+	err = connection.Close()
+	if err != nil {
+		return fmt.Errorf("closing notify socket connection: %w", err)
+	}
+	return nil
+}
+
+// status returns a `STATUS=` line, replacing newlines since
+// each notification line must be a single line.
+func status(format string, args ...any) string {
+	return "STATUS=" + strings.ReplaceAll(fmt.Sprintf(format, args...), "\n", " ")
+}
@@ -0,0 +1,93 @@
+package sdnotify
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/qdm12/goservices"
+)
+
+// Settings is the settings for the systemd notify service wrapper.
+type Settings struct {
+	// Service is the root service to wrap.
+	// It must be set for settings validation to pass.
+	Service goservices.Service
+	// Socket is the systemd notification unix datagram socket path.
+	// A path starting with `@` designates an abstract socket.
+	// It defaults to the `NOTIFY_SOCKET` environment variable value,
+	// and notifications are disabled if it is the empty string.
+	Socket *string
+	// WatchdogInterval is the interval between `WATCHDOG=1` pings.
+	// If left unset, `New` sets it to half of the `WATCHDOG_USEC`
+	// environment variable value if `WATCHDOG_PID` is unset or
+	// matches the current process ID. It otherwise defaults to 0,
+	// which disables watchdog pings.
+	WatchdogInterval *time.Duration
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Socket == nil {
+		socket := os.Getenv("NOTIFY_SOCKET")
+		s.Socket = &socket
+	}
+
+	if s.WatchdogInterval == nil {
+		s.WatchdogInterval = new(time.Duration)
+	}
+}
+
+var (
+	ErrServiceIsNil             = errors.New("service is nil")
+	ErrWatchdogIntervalNegative = errors.New("watchdog interval is negative")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	if s.Service == nil {
+		return fmt.Errorf("%w", ErrServiceIsNil)
+	}
+
+	if *s.WatchdogInterval < 0 {
+		return fmt.Errorf("%w: %s", ErrWatchdogIntervalNegative, *s.WatchdogInterval)
+	}
+
+	return nil
+}
+
+var (
+	ErrWatchdogUsecNotValid = errors.New("WATCHDOG_USEC value is not valid")
+	ErrWatchdogPIDNotValid  = errors.New("WATCHDOG_PID value is not valid")
+)
+
+// watchdogIntervalFromEnv returns half of the `WATCHDOG_USEC` duration,
+// or 0 if the watchdog is not enabled for this process.
+func watchdogIntervalFromEnv() (interval time.Duration, err error) {
+	usecString := os.Getenv("WATCHDOG_USEC")
+	if usecString == "" {
+		return 0, nil
+	}
+
+	pidString := os.Getenv("WATCHDOG_PID")
+	if pidString != "" {
+		pid, err := strconv.Atoi(pidString)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrWatchdogPIDNotValid, pidString)
+		}
+		if pid != os.Getpid() {
+			return 0, nil
+		}
+	}
+
+	usec, err := strconv.ParseUint(usecString, 10, 63)
+	if err != nil || usec == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrWatchdogUsecNotValid, usecString)
+	}
+
+	const half = 2
+	return time.Duration(usec) * time.Microsecond / half, nil
+}
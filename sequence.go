@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 )
 
 var _ Service = (*Sequence)(nil)
@@ -16,9 +17,15 @@ type Sequence struct {
 	servicesStart  []Service
 	servicesStop   []Service
 	hooks          Hooks
+	drainDelay     time.Duration
+	readyTimeout   time.Duration
+	profilerLabels bool
+	profiler       profiler
+	crashReporter  *crashReporter
 	startStopMutex sync.Mutex
 	state          State
 	stateMutex     sync.RWMutex
+	draining       bool
 	fanIn          *errorsFanIn
 	// runningServices contains service names that are currently running.
 	runningServices map[string]struct{}
@@ -42,16 +49,39 @@ func NewSequence(settings SequenceSettings) (sequence *Sequence, err error) {
 	servicesStop := make([]Service, len(settings.ServicesStop))
 	copy(servicesStop, settings.ServicesStop)
 
+	if settings.Watchdog != nil {
+		servicesStart = wrapWithWatchdogs(servicesStart, *settings.Watchdog)
+		// Service names are unique and services to stop are the
+		// services to start, as enforced by settings validation.
+		nameToWatchdog := make(map[string]Service, len(servicesStart))
+		for _, watchdog := range servicesStart {
+			nameToWatchdog[watchdog.String()] = watchdog
+		}
+		for i, service := range servicesStop {
+			servicesStop[i] = nameToWatchdog[service.String()]
+		}
+	}
+
 	return &Sequence{
 		name:            settings.Name,
 		servicesStart:   servicesStart,
 		servicesStop:    servicesStop,
 		hooks:           settings.Hooks,
+		drainDelay:      settings.DrainDelay,
+		readyTimeout:    settings.ReadinessTimeout,
+		profilerLabels:  settings.ProfilerLabels || settings.Watchdog != nil,
+		crashReporter:   newCrashReporter(settings.CrashReports),
 		state:           StateStopped,
 		runningServices: make(map[string]struct{}, len(servicesStart)),
 	}, nil
 }
 
+// CrashReports returns the crash reports captured, from the
+// oldest to the most recent one, if crash reports are enabled.
+func (s *Sequence) CrashReports() (reports []CrashReport) {
+	return s.crashReporter.list()
+}
+
 func (s *Sequence) String() string {
 	if s.name == "" {
 		return "sequence"
@@ -93,27 +123,53 @@ func (s *Sequence) Start(ctx context.Context) (runError <-chan error, startErr e
 		return nil, fmt.Errorf("%s: %w", s, ErrAlreadyStarted)
 	}
 
+	s.stateMutex.Lock()
 	s.state = StateStarting
+	s.draining = false
+	s.stateMutex.Unlock()
+	s.profiler = newProfiler(ctx, s.profilerLabels, s.String())
 
-	var fanInErrorCh <-chan serviceError
+	var fanInErrorCh <-chan *CrashError
 	s.fanIn, fanInErrorCh = newErrorsFanIn()
 
 	for _, service := range s.servicesStart {
 		serviceString := service.String()
 
-		s.hooks.OnStart(serviceString)
-		serviceRunError, err := service.Start(ctx)
-		s.hooks.OnStarted(serviceString, err)
+		var serviceRunError <-chan error
+		var err error
+		s.profiler.do(ctx, s.profiler.childPath(serviceString), phaseStart,
+			func(ctx context.Context) {
+				s.hooks.OnStart(serviceString)
+				serviceRunError, err = service.Start(ctx)
+				s.hooks.OnStarted(serviceString, err)
+			})
 
 		if err != nil {
 			err = addCtxErrorIfNeeded(err, ctx.Err())
 			_ = s.stop()
-			return nil, fmt.Errorf("starting %s: %w", serviceString, err)
+			return nil, &StartError{Service: serviceString, Err: err}
 		}
 
 		s.runningServices[serviceString] = struct{}{}
 
+		s.crashReporter.started(serviceString)
 		s.fanIn.add(serviceString, serviceRunError)
+
+		if s.readyTimeout == 0 {
+			continue
+		}
+
+		crashErr, err := waitReady(ctx, service, s.readyTimeout, fanInErrorCh)
+		if crashErr != nil {
+			// A service crashed before the sequence finished starting,
+			// which is effectively a start error for this service.
+			delete(s.runningServices, crashErr.Service)
+			_ = s.stop()
+			return nil, &StartError{Service: crashErr.Service, Err: crashErr.Err}
+		} else if err != nil {
+			_ = s.stop()
+			return nil, &StartError{Service: serviceString, Err: err}
+		}
 	}
 
 	// Hold the state mutex until the intercept run error goroutine is ready
@@ -143,13 +199,14 @@ func (s *Sequence) Start(ctx context.Context) (runError <-chan error, startErr e
 // to the output channel and finally closes this channel.
 // If the stop channel triggers, the function returns.
 func (s *Sequence) interceptRunError(ready chan<- struct{},
-	input <-chan serviceError, output chan<- error) {
+	input <-chan *CrashError, output chan<- error) {
 	defer close(s.interceptDone)
+	s.profiler.labelGoroutine(phaseRun)
 	close(ready)
 
 	select {
 	case <-s.interceptStop:
-	case serviceErr := <-input:
+	case crashErr := <-input:
 		// Lock the state mutex in case we are stopping
 		// or trying to stop the sequence at the same time.
 		s.stateMutex.Lock()
@@ -163,29 +220,76 @@ func (s *Sequence) interceptRunError(ready chan<- struct{},
 		// The first and only service fanned-in run error was
 		// caught and we are not currently stopping the sequence.
 		s.state = StateCrashed
-		delete(s.runningServices, serviceErr.serviceName)
+		delete(s.runningServices, crashErr.Service)
 		s.stateMutex.Unlock()
 
-		s.hooks.OnCrash(serviceErr.serviceName, serviceErr.err)
+		s.hooks.OnCrash(crashErr.Service, crashErr.Err)
+		s.crashReporter.report(s.hooks, s.profiler.childPath(crashErr.Service),
+			crashErr.Service, crashErr.Err, 0)
 		_ = s.stop()
-		output <- &serviceErr
+		output <- crashErr
 		close(output)
 	}
 }
 
+// Ready returns a nil error if the sequence is running and all
+// its services implementing `Readier` are ready. Otherwise, it
+// returns an error wrapping `ErrNotReady` describing which
+// services are not ready.
+func (s *Sequence) Ready() (err error) {
+	s.stateMutex.RLock()
+	state, draining := s.state, s.draining
+	s.stateMutex.RUnlock()
+	if state != StateRunning {
+		return fmt.Errorf("%w: %s is %s", ErrNotReady, s, state)
+	} else if draining {
+		return fmt.Errorf("%w: %s is draining", ErrNotReady, s)
+	}
+	return servicesReady(s.servicesStart)
+}
+
+// Drain flips the sequence readiness off and notifies services
+// implementing `Drainer` of the drain phase, returning once they
+// are all drained or the context is canceled. It is called by `Stop`
+// if a drain delay is set and the sequence was not drained already, and
+// when the sequence is drained by a parent service management type.
+func (s *Sequence) Drain(ctx context.Context) {
+	_ = s.drain(ctx)
+}
+
+func (s *Sequence) drain(ctx context.Context) (drainers int) {
+	s.stateMutex.Lock()
+	s.draining = true
+	s.stateMutex.Unlock()
+	return drainServices(ctx, s.servicesStart)
+}
+
 // Stop stops running services of the sequence
 // in the order specified by the sequence of services.
 // If an error occurs for any of the service stop,
 // the other running services will still be stopped.
-// Only the first non nil service stop error encountered
-// is returned, but the hooks can be used to process each
-// error returned.
+// All the service stop errors are returned aggregated in a
+// `*StopErrors` error, and the hooks can also be used to process
+// each error returned.
 // If the sequence is already stopped, the `ErrAlreadyStopped` error
 // is returned.
 func (s *Sequence) Stop() (err error) {
 	s.startStopMutex.Lock()
 	defer s.startStopMutex.Unlock()
 
+	if s.drainDelay > 0 {
+		s.stateMutex.RLock()
+		state, draining := s.state, s.draining
+		s.stateMutex.RUnlock()
+		// Skip the drain phase if the sequence was already drained,
+		// for example by a parent service management type.
+		if state == StateRunning && !draining {
+			// A service crashing during the drain phase is handled
+			// as usual, and the sequence state becomes crashed.
+			runDrainPhase(s, s.drainDelay)
+		}
+	}
+
 	s.stateMutex.Lock()
 	switch s.state {
 	case StateRunning: // continue stopping the sequence
@@ -217,7 +321,9 @@ func (s *Sequence) Stop() (err error) {
 	close(s.interceptStop)
 	<-s.interceptDone
 
+	s.stateMutex.Lock()
 	s.state = StateStopped
+	s.stateMutex.Unlock()
 
 	return err
 }
@@ -225,9 +331,10 @@ func (s *Sequence) Stop() (err error) {
 // stop stops all running services in the sequence.
 // If a service fails to stop in the sequence, its error
 // is returned but the other services are still stopped.
-// All service stop errors are wrapped together in the format
-// stopping <name_1>: %w; stopping <name_2>: %w; ...
-// and can be checked individually with errors.Is(err, ErrDefined).
+// All service stop errors are aggregated in a `*StopErrors` error
+// formatted as stopping <name_1>: %w; stopping <name_2>: %w; ...
+// and can be checked individually with errors.Is(err, ErrDefined)
+// or errors.As(err, &stopErr) with `stopErr` of type `*StopError`.
 // Hooks can be used to access each stopping and stop result.
 func (s *Sequence) stop() (err error) {
 	for _, service := range s.servicesStop {
@@ -238,9 +345,13 @@ func (s *Sequence) stop() (err error) {
 			continue
 		}
 
-		s.hooks.OnStop(serviceString)
-		stopErr := service.Stop()
-		s.hooks.OnStopped(serviceString, stopErr)
+		var stopErr error
+		s.profiler.do(context.Background(), s.profiler.childPath(serviceString), phaseStop,
+			func(context.Context) {
+				s.hooks.OnStop(serviceString)
+				stopErr = service.Stop()
+				s.hooks.OnStopped(serviceString, stopErr)
+			})
 		err = addStopError(err, serviceString, stopErr)
 		delete(s.runningServices, serviceString)
 	}
@@ -2,6 +2,7 @@ package goservices
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/qdm12/goservices/hooks"
 )
@@ -20,6 +21,43 @@ type SequenceSettings struct {
 	// each service. It defaults to a noop hooks
 	// implementation.
 	Hooks Hooks
+	// ProfilerLabels enables setting pprof goroutine labels
+	// and runtime/trace tasks and regions for the start and stop
+	// of each service, so profiles and execution traces can be
+	// attributed to individual services. See `ProfilerLabelService`
+	// and `ProfilerLabelPhase` for the label keys used.
+	// Note it is automatically enabled if the sequence is started by
+	// a parent service management type with profiler labels enabled.
+	ProfilerLabels bool
+	// Watchdog, if set, wraps each service with a watchdog
+	// using these settings, reporting service starts and stops
+	// taking too long. Its `Service` field is ignored.
+	// Setting it also enables profiler labels, see `ProfilerLabels`.
+	// It defaults to nil, meaning no watchdog is used.
+	Watchdog *WatchdogSettings
+	// CrashReports, if set, enables capturing a crash report
+	// when a service crashes. Crash reports are retrievable with
+	// the `CrashReports` method, are passed to the hooks if they
+	// implement `HooksCrashReport` and can be written as JSON files
+	// to a crash directory. It defaults to nil, meaning crash
+	// reports are disabled.
+	CrashReports *CrashReportSettings
+	// ReadinessTimeout, if positive, makes the sequence start wait
+	// for each service implementing `Readier` to be ready, and not
+	// merely started, before starting the next service. A service not
+	// ready within this timeout fails the sequence start with an error
+	// wrapping `ErrNotReady`. It defaults to 0, meaning the sequence
+	// does not wait for the readiness of its services.
+	ReadinessTimeout time.Duration
+	// DrainDelay, if positive, enables a drain phase when `Stop` is
+	// called, before stopping any service. During the drain phase, the
+	// sequence readiness is flipped off and services implementing `Drainer`
+	// in the tree are notified. The drain phase ends once all drainers
+	// are drained, or once the drain delay elapses. If no service
+	// implements `Drainer`, the full delay is waited, for example for a
+	// load balancer to notice the readiness change.
+	// It defaults to 0, meaning there is no drain phase.
+	DrainDelay time.Duration
 }
 
 // setDefaults sets the defaults for the sequence settings.
@@ -27,6 +65,18 @@ func (s *SequenceSettings) setDefaults() {
 	if s.Hooks == nil {
 		s.Hooks = hooks.NewNoop()
 	}
+
+	if s.Watchdog != nil {
+		watchdog := *s.Watchdog
+		watchdog.setDefaults()
+		s.Watchdog = &watchdog
+	}
+
+	if s.CrashReports != nil {
+		crashReports := *s.CrashReports
+		crashReports.setDefaults()
+		s.CrashReports = &crashReports
+	}
 }
 
 // validate validates the sequence settings.
@@ -64,6 +114,21 @@ func (s SequenceSettings) validate() (err error) {
 		return fmt.Errorf("%w: %s", ErrServicesNotUnique, errMessage)
 	}
 
+	if s.DrainDelay < 0 {
+		return fmt.Errorf("%w: %s", ErrDrainDelayNotValid, s.DrainDelay)
+	}
+
+	if s.ReadinessTimeout < 0 {
+		return fmt.Errorf("%w: %s", ErrReadinessTimeoutNotValid, s.ReadinessTimeout)
+	}
+
+	if s.Watchdog != nil {
+		err = s.Watchdog.validateWatch()
+		if err != nil {
+			return fmt.Errorf("watchdog: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -2,6 +2,7 @@ package goservices
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/qdm12/goservices/hooks"
@@ -130,6 +131,24 @@ func Test_SequenceSettings_Validate(t *testing.T) {
 			errMessage: "services are not unique: services dummy one is duplicated twice " +
 				"and dummy two is duplicated twice",
 		},
+		"negative drain delay": {
+			settings: SequenceSettings{
+				ServicesStart: []Service{dummyServiceOne},
+				ServicesStop:  []Service{dummyServiceOne},
+				DrainDelay:    -time.Second,
+			},
+			errSentinel: ErrDrainDelayNotValid,
+			errMessage:  "drain delay is not valid: -1s",
+		},
+		"negative readiness timeout": {
+			settings: SequenceSettings{
+				ServicesStart:    []Service{dummyServiceOne},
+				ServicesStop:     []Service{dummyServiceOne},
+				ReadinessTimeout: -time.Second,
+			},
+			errSentinel: ErrReadinessTimeoutNotValid,
+			errMessage:  "readiness timeout is not valid: -1s",
+		},
 		"success": {
 			settings: SequenceSettings{
 				ServicesStart: []Service{dummyServiceOne},
@@ -327,7 +327,7 @@ func Test_Sequence_interceptRunError(t *testing.T) {
 		}
 
 		ready := make(chan struct{})
-		input := make(chan serviceError)
+		input := make(chan *CrashError)
 		output := make(chan error)
 		close(output) // do not write to output channel
 
@@ -336,10 +336,9 @@ func Test_Sequence_interceptRunError(t *testing.T) {
 		<-ready
 
 		errTest := errors.New("test error")
-		input <- serviceError{
-			format:      errorFormatCrash,
-			serviceName: "A",
-			err:         errTest,
+		input <- &CrashError{
+			Service: "A",
+			Err:     errTest,
 		}
 
 		<-sequence.interceptDone
@@ -387,7 +386,7 @@ func Test_Sequence_interceptRunError(t *testing.T) {
 		}
 
 		ready := make(chan struct{})
-		input := make(chan serviceError)
+		input := make(chan *CrashError)
 		output := make(chan error)
 
 		go sequence.interceptRunError(ready, input, output)
@@ -396,10 +395,9 @@ func Test_Sequence_interceptRunError(t *testing.T) {
 
 		errTest := errors.New("test error")
 		hooks.EXPECT().OnCrash("A", errTest)
-		input <- serviceError{
-			format:      errorFormatCrash,
-			serviceName: "A",
-			err:         errTest,
+		input <- &CrashError{
+			Service: "A",
+			Err:     errTest,
 		}
 
 		err := <-output
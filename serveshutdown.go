@@ -0,0 +1,248 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// ServeShutdowner is the constraint of servers run by a `ServeShutdown`
+// service, serving connections accepted on a listener, such as an
+// `*http.Server` or a gRPC `*grpc.Server`.
+type ServeShutdowner interface {
+	Serve(listener net.Listener) error
+}
+
+type (
+	shutdowner interface {
+		Shutdown(ctx context.Context) error
+	}
+	gracefulStopper interface{ GracefulStop() }
+	stopper         interface{ Stop() }
+	closer          interface{ Close() error }
+)
+
+var _ Service = (*ServeShutdown[ServeShutdowner])(nil)
+
+// ServeShutdown is a service listening on an address and serving
+// a server on the listener, for any server with a `Serve(net.Listener)`
+// method and a `Shutdown(ctx) error`, `GracefulStop()` or `Stop()`
+// method to stop it. The server `Serve` method returning whilst the
+// service is running is a crash, and the server is forcibly closed
+// if it does not shut down within the shutdown timeout.
+type ServeShutdown[S ServeShutdowner] struct {
+	// Dependencies injected
+	settings ServeShutdownSettings[S]
+
+	// Internal fields
+	server         S
+	serverMutex    sync.RWMutex
+	startStopMutex sync.Mutex
+	state          State
+	stateMutex     sync.RWMutex
+	listener       net.Listener
+	address        string
+	addressMutex   sync.RWMutex
+	serveDone      chan struct{}
+}
+
+// NewServeShutdown creates a new serve shutdown service
+// using the settings given.
+func NewServeShutdown[S ServeShutdowner](settings ServeShutdownSettings[S]) (
+	service *ServeShutdown[S], err error) {
+	settings.setDefaults()
+	err = settings.validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &ServeShutdown[S]{
+		settings: settings,
+		state:    StateStopped,
+	}, nil
+}
+
+func (s *ServeShutdown[S]) String() string {
+	return s.settings.Name
+}
+
+// Server returns the server created at the last start,
+// or the zero value if the service was never started.
+func (s *ServeShutdown[S]) Server() (server S) {
+	s.serverMutex.RLock()
+	defer s.serverMutex.RUnlock()
+	return s.server
+}
+
+// GetAddress obtains the address the server is listening on.
+// It is only set after `Start` returns without error.
+func (s *ServeShutdown[S]) GetAddress() (address string) {
+	s.addressMutex.RLock()
+	defer s.addressMutex.RUnlock()
+	return s.address
+}
+
+// Start creates a new server, listens on the address from the settings,
+// using the context to cancel listening, and then serves the server on
+// the listener.
+func (s *ServeShutdown[S]) Start(ctx context.Context) (runError <-chan error, err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.RLock()
+	state := s.state
+	s.stateMutex.RUnlock()
+	if state == StateRunning {
+		return nil, fmt.Errorf("%s: %w", s, ErrAlreadyStarted)
+	}
+
+	s.state = StateStarting
+
+	server := s.settings.NewServer()
+	if isNilServer(server) {
+		s.state = StateStopped
+		return nil, fmt.Errorf("creating server: %w", ErrServerIsNil)
+	}
+	s.serverMutex.Lock()
+	s.server = server
+	s.serverMutex.Unlock()
+
+	listenConfig := net.ListenConfig{}
+	listener, err := listenConfig.Listen(ctx, s.settings.Network, s.settings.Address)
+	if err != nil {
+		s.state = StateStopped
+		return nil, err
+	}
+	s.listener = listener
+
+	s.addressMutex.Lock()
+	s.address = listener.Addr().String()
+	s.addressMutex.Unlock()
+
+	runErrorBiDirectional := make(chan error)
+	s.serveDone = make(chan struct{})
+	ready := make(chan struct{})
+
+	// Hold the state mutex locked in case the server
+	// Serve method returns an error instantly.
+	s.stateMutex.Lock()
+	go s.serve(ready, runErrorBiDirectional)
+	<-ready
+	s.state = StateRunning
+	s.stateMutex.Unlock()
+
+	return runErrorBiDirectional, nil
+}
+
+func (s *ServeShutdown[S]) serve(ready chan<- struct{}, runError chan<- error) {
+	close(ready)
+	err := s.server.Serve(s.listener)
+
+	s.stateMutex.Lock()
+	if s.state == StateStopping {
+		// Serve returns with different errors depending on the
+		// server when it is stopped, so the error is discarded.
+		s.stateMutex.Unlock()
+		close(s.serveDone)
+		return
+	}
+	s.state = StateCrashed
+	s.stateMutex.Unlock()
+	close(s.serveDone)
+
+	if err == nil {
+		err = fmt.Errorf("%w", ErrServeReturned)
+	}
+	runError <- err
+}
+
+// Stop shuts down the server gracefully, and forcibly closes
+// it if it does not shut down within the shutdown timeout, in which
+// case an error wrapping `ErrShutdownTimeout` is returned.
+func (s *ServeShutdown[S]) Stop() (err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.Lock()
+	switch s.state {
+	case StateRunning: // continue stopping the server
+	case StateCrashed: // server is already stopped
+		s.state = StateStopped
+		s.stateMutex.Unlock()
+		<-s.serveDone
+		_ = s.listener.Close()
+		return nil
+	case StateStopped:
+		s.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", s, ErrAlreadyStopped)
+	case StateStarting, StateStopping:
+		s.stateMutex.Unlock()
+		panic("bad implementation code: this code path should be unreachable")
+	}
+	s.state = StateStopping
+	s.stateMutex.Unlock()
+
+	serveReturning, err := s.shutdown()
+	if serveReturning {
+		<-s.serveDone
+	}
+	_ = s.listener.Close()
+
+	s.stateMutex.Lock()
+	s.state = StateStopped
+	s.stateMutex.Unlock()
+	return err
+}
+
+// shutdown shuts down the server using its `Shutdown(ctx) error`,
+// `GracefulStop()` or `Stop()` method, in this order of preference.
+// If the graceful shutdown does not complete within the shutdown timeout,
+// the server is forcibly closed using its `Close() error` or `Stop()`
+// method, and an error wrapping `ErrShutdownTimeout` is returned.
+// The `serveReturning` boolean is false if the server cannot be forcibly
+// closed, in which case its `Serve` method may never return.
+func (s *ServeShutdown[S]) shutdown() (serveReturning bool, err error) {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(),
+		s.settings.ShutdownTimeout)
+	defer cancel()
+
+	switch server := any(s.server).(type) {
+	case shutdowner:
+		err = server.Shutdown(shutdownCtx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return true, err
+		}
+	case gracefulStopper:
+		done := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return true, nil
+		case <-shutdownCtx.Done():
+		}
+	case stopper:
+		server.Stop()
+		return true, nil
+	}
+
+	err = fmt.Errorf("%w after %s", ErrShutdownTimeout, s.settings.ShutdownTimeout)
+	switch server := any(s.server).(type) {
+	case closer:
+		closeErr := server.Close()
+		if closeErr != nil {
+			err = fmt.Errorf("%w (and closing: %w)", err, closeErr)
+		}
+	case stopper:
+		server.Stop()
+	default:
+		// The server cannot be forcibly closed, so do not
+		// wait for its Serve method to return.
+		return false, fmt.Errorf("%w: server cannot be forcibly closed", err)
+	}
+	return true, err
+}
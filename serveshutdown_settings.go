@@ -0,0 +1,78 @@
+package goservices
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ServeShutdownSettings contains settings for a `ServeShutdown` service.
+type ServeShutdownSettings[S ServeShutdowner] struct {
+	// Name is the name of the service, used for its `String` method.
+	// It defaults to "server" if left unset.
+	Name string
+	// NewServer creates the server to serve on the listener, and is
+	// called on each start since servers such as `*http.Server` cannot
+	// serve again once shut down. The server type must also implement
+	// one of the `Shutdown(ctx) error`, `GracefulStop()` or `Stop()`
+	// methods. It must be set for settings validation to succeed.
+	NewServer func() S
+	// Network is the network to listen on, for example "tcp" or "unix".
+	// It defaults to "tcp" if left unset.
+	Network string
+	// Address is the address to listen on.
+	// It defaults to the empty string, which is a random port for TCP.
+	Address string
+	// ShutdownTimeout is the maximum duration to wait for the server
+	// to shut down gracefully, after which it is forcibly closed.
+	// It defaults to 3 seconds if left unset.
+	ShutdownTimeout time.Duration
+}
+
+// setDefaults sets the defaults for the serve shutdown settings.
+func (s *ServeShutdownSettings[S]) setDefaults() {
+	if s.Name == "" {
+		s.Name = "server"
+	}
+
+	if s.Network == "" {
+		s.Network = "tcp"
+	}
+
+	if s.ShutdownTimeout == 0 {
+		const defaultShutdownTimeout = 3 * time.Second
+		s.ShutdownTimeout = defaultShutdownTimeout
+	}
+}
+
+// validate validates the serve shutdown settings.
+func (s ServeShutdownSettings[S]) validate() (err error) {
+	if s.NewServer == nil {
+		return fmt.Errorf("%w", ErrNewServerIsNil)
+	}
+
+	serverType := reflect.TypeFor[S]()
+	if !serverType.Implements(reflect.TypeFor[shutdowner]()) &&
+		!serverType.Implements(reflect.TypeFor[gracefulStopper]()) &&
+		!serverType.Implements(reflect.TypeFor[stopper]()) {
+		return fmt.Errorf("%w: %s", ErrServerNoShutdown, serverType)
+	}
+
+	if s.ShutdownTimeout < 0 {
+		return fmt.Errorf("%w: %s", ErrShutdownTimeoutNotValid, s.ShutdownTimeout)
+	}
+	return nil
+}
+
+func isNilServer(server ServeShutdowner) bool {
+	if server == nil {
+		return true
+	}
+	value := reflect.ValueOf(server)
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Func, reflect.Chan, reflect.Slice:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
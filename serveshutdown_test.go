@@ -0,0 +1,211 @@
+package goservices
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ServeShutdown_httpServer(t *testing.T) {
+	t.Parallel()
+
+	service, err := NewServeShutdown(ServeShutdownSettings[*http.Server]{
+		Name: "http",
+		NewServer: func() *http.Server {
+			return &http.Server{
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				}),
+				ReadHeaderTimeout: time.Second,
+			}
+		},
+		Address: "127.0.0.1:0",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http", service.String())
+
+	// The service is restarted to check a new server
+	// is created, since an http server cannot serve
+	// again once shut down.
+	const starts = 2
+	for range starts {
+		runError, err := service.Start(context.Background())
+		require.NoError(t, err)
+
+		client := &http.Client{Timeout: time.Second}
+		request, err := http.NewRequestWithContext(context.Background(),
+			http.MethodGet, "http://"+service.GetAddress(), nil)
+		require.NoError(t, err)
+		response, err := client.Do(request)
+		require.NoError(t, err)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusTeapot, response.StatusCode)
+
+		err = service.Stop()
+		require.NoError(t, err)
+		select {
+		case err := <-runError:
+			t.Fatalf("unexpected run error: %s", err)
+		default:
+		}
+	}
+
+	err = service.Stop()
+	assert.ErrorIs(t, err, ErrAlreadyStopped)
+}
+
+// testGRPCServer mimics the gRPC server methods.
+type testGRPCServer struct {
+	serveErr    chan error
+	stopped     chan struct{}
+	stopOnce    sync.Once
+	gracefulHit chan struct{}
+}
+
+func newTestGRPCServer() *testGRPCServer {
+	return &testGRPCServer{
+		serveErr:    make(chan error),
+		stopped:     make(chan struct{}),
+		gracefulHit: make(chan struct{}, 1),
+	}
+}
+
+func (s *testGRPCServer) Serve(listener net.Listener) error {
+	defer listener.Close()
+	select {
+	case err := <-s.serveErr:
+		return err
+	case <-s.stopped:
+		return nil
+	}
+}
+
+// GracefulStop blocks until Stop is called, like
+// gRPC with a client connection still open.
+func (s *testGRPCServer) GracefulStop() {
+	s.gracefulHit <- struct{}{}
+	<-s.stopped
+}
+
+func (s *testGRPCServer) Stop() {
+	s.stopOnce.Do(func() { close(s.stopped) })
+}
+
+func Test_ServeShutdown_crash(t *testing.T) {
+	t.Parallel()
+
+	server := newTestGRPCServer()
+	service, err := NewServeShutdown(ServeShutdownSettings[*testGRPCServer]{
+		NewServer: func() *testGRPCServer { return server },
+		Address:   "127.0.0.1:0",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "server", service.String())
+	assert.Nil(t, service.Server())
+
+	runError, err := service.Start(context.Background())
+	require.NoError(t, err)
+	assert.Same(t, server, service.Server())
+
+	errTest := errors.New("test error")
+	server.serveErr <- errTest
+	assert.ErrorIs(t, <-runError, errTest)
+
+	err = service.Stop()
+	assert.NoError(t, err)
+}
+
+func Test_ServeShutdown_shutdownTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newTestGRPCServer()
+	service, err := NewServeShutdown(ServeShutdownSettings[*testGRPCServer]{
+		NewServer:       func() *testGRPCServer { return server },
+		Address:         "127.0.0.1:0",
+		ShutdownTimeout: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, err = service.Start(context.Background())
+	require.NoError(t, err)
+
+	err = service.Stop()
+	assert.ErrorIs(t, err, ErrShutdownTimeout)
+	assert.EqualError(t, err, "shutdown timeout exceeded after 20ms")
+	<-server.gracefulHit
+}
+
+func Test_ServeShutdown_Start_nilServer(t *testing.T) {
+	t.Parallel()
+
+	service, err := NewServeShutdown(ServeShutdownSettings[*http.Server]{
+		NewServer: func() *http.Server { return nil },
+	})
+	require.NoError(t, err)
+
+	_, err = service.Start(context.Background())
+	assert.ErrorIs(t, err, ErrServerIsNil)
+	assert.EqualError(t, err, "creating server: server is nil")
+}
+
+func Test_ServeShutdownSettings_validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings   interface{ validate() error }
+		errWrapped error
+		errMessage string
+	}{
+		"nil new server function": {
+			settings:   ServeShutdownSettings[*http.Server]{},
+			errWrapped: ErrNewServerIsNil,
+			errMessage: "new server function is nil",
+		},
+		"server without shutdown method": {
+			settings: ServeShutdownSettings[serverFunc]{
+				NewServer: func() serverFunc {
+					return func(net.Listener) error { return nil }
+				},
+			},
+			errWrapped: ErrServerNoShutdown,
+			errMessage: "server has no shutdown method: goservices.serverFunc",
+		},
+		"negative shutdown timeout": {
+			settings: ServeShutdownSettings[*http.Server]{
+				NewServer:       func() *http.Server { return &http.Server{} }, //nolint:gosec
+				ShutdownTimeout: -time.Second,
+			},
+			errWrapped: ErrShutdownTimeoutNotValid,
+			errMessage: "shutdown timeout is not valid: -1s",
+		},
+		"valid settings": {
+			settings: ServeShutdownSettings[*testGRPCServer]{
+				NewServer: newTestGRPCServer,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.settings.validate()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
+
+type serverFunc func(listener net.Listener) error
+
+func (f serverFunc) Serve(listener net.Listener) error { return f(listener) }
@@ -0,0 +1,151 @@
+// Package signals implements a service listening for OS signals.
+package signals
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Service = (*Service)(nil)
+
+// Service is a service subscribing to OS signals when started.
+// On a terminating signal, it sends a run error wrapping
+// `goservices.ErrSignalReceived`, and on other signals, it calls
+// the corresponding callback. It can be used as a member of a
+// `goservices.Group` to stop the group cleanly on SIGTERM.
+type Service struct {
+	// Dependencies injected
+	settings Settings
+
+	// Internal fields
+	startStopMutex sync.Mutex
+	state          goservices.State
+	stateMutex     sync.RWMutex
+	signals        chan os.Signal
+	stop           chan struct{}
+	done           chan struct{}
+}
+
+// New creates a new signals service using the settings given.
+func New(settings Settings) (service *Service, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &Service{
+		settings: settings,
+		state:    goservices.StateStopped,
+	}, nil
+}
+
+func (s *Service) String() string {
+	if *s.settings.Name == "" {
+		return "signals"
+	}
+	return *s.settings.Name + " signals"
+}
+
+// Start subscribes to the signals specified in the settings.
+func (s *Service) Start(_ context.Context) (runError <-chan error, err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.RLock()
+	state := s.state
+	s.stateMutex.RUnlock()
+	if state == goservices.StateRunning {
+		return nil, fmt.Errorf("%s: %w", s, goservices.ErrAlreadyStarted)
+	}
+
+	s.state = goservices.StateStarting
+
+	subscribed := make([]os.Signal, 0, len(s.settings.Terminate)+len(s.settings.Callbacks))
+	subscribed = append(subscribed, s.settings.Terminate...)
+	for callbackSignal := range s.settings.Callbacks {
+		subscribed = append(subscribed, callbackSignal)
+	}
+	s.signals = make(chan os.Signal, 1)
+	signal.Notify(s.signals, subscribed...)
+
+	runErrorBiDirectional := make(chan error)
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	ready := make(chan struct{})
+
+	// Hold the state mutex locked in case a terminating
+	// signal is received instantly.
+	s.stateMutex.Lock()
+	go s.listen(ready, runErrorBiDirectional)
+	<-ready
+	s.state = goservices.StateRunning
+	s.stateMutex.Unlock()
+
+	return runErrorBiDirectional, nil
+}
+
+func (s *Service) listen(ready chan<- struct{}, runError chan<- error) {
+	defer close(s.done)
+	close(ready)
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case receivedSignal := <-s.signals:
+			if !slices.Contains(s.settings.Terminate, receivedSignal) {
+				s.settings.Callbacks[receivedSignal](receivedSignal)
+				continue
+			}
+
+			s.stateMutex.Lock()
+			if s.state == goservices.StateStopping {
+				s.stateMutex.Unlock()
+				return
+			}
+			s.state = goservices.StateCrashed
+			s.stateMutex.Unlock()
+			signal.Stop(s.signals)
+			runError <- fmt.Errorf("%w: %s", goservices.ErrSignalReceived, receivedSignal)
+			return
+		}
+	}
+}
+
+// Stop unsubscribes from the signals and waits for
+// an eventual callback in progress to complete.
+func (s *Service) Stop() (err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.Lock()
+	switch s.state {
+	case goservices.StateRunning: // continue stopping the service
+	case goservices.StateCrashed: // terminating signal received
+		s.stateMutex.Unlock()
+		<-s.done
+		s.state = goservices.StateStopped
+		return nil
+	case goservices.StateStopped:
+		s.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", s, goservices.ErrAlreadyStopped)
+	case goservices.StateStarting, goservices.StateStopping:
+		s.stateMutex.Unlock()
+		panic("bad implementation code: this code path should be unreachable")
+	}
+	s.state = goservices.StateStopping
+	s.stateMutex.Unlock()
+
+	signal.Stop(s.signals)
+	close(s.stop)
+	<-s.done
+	s.state = goservices.StateStopped
+	return nil
+}
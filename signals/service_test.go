@@ -0,0 +1,80 @@
+//go:build unix
+
+package signals
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Each test uses a distinct signal since signals are
+// delivered to every subscribed channel of the process.
+
+func Test_Service_terminate(t *testing.T) {
+	t.Parallel()
+
+	service, err := New(Settings{Terminate: []os.Signal{syscall.SIGUSR1}})
+	require.NoError(t, err)
+
+	runError, err := service.Start(context.Background())
+	require.NoError(t, err)
+
+	err = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	require.NoError(t, err)
+
+	select {
+	case err = <-runError:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for run error")
+	}
+	assert.ErrorIs(t, err, goservices.ErrSignalReceived)
+	assert.EqualError(t, err, "signal received: user defined signal 1")
+
+	err = service.Stop()
+	assert.NoError(t, err)
+}
+
+func Test_Service_callback(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan os.Signal)
+	name := "test"
+	service, err := New(Settings{
+		Name:      &name,
+		Terminate: []os.Signal{},
+		Callbacks: map[os.Signal]func(os.Signal){
+			syscall.SIGUSR2: func(signal os.Signal) { received <- signal },
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "test signals", service.String())
+
+	runError, err := service.Start(context.Background())
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		err = syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+		require.NoError(t, err)
+		select {
+		case signal := <-received:
+			assert.Equal(t, syscall.SIGUSR2, signal)
+		case err = <-runError:
+			t.Fatalf("unexpected run error: %s", err)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for callback")
+		}
+	}
+
+	err = service.Stop()
+	require.NoError(t, err)
+
+	err = service.Stop()
+	assert.ErrorIs(t, err, goservices.ErrAlreadyStopped)
+}
@@ -0,0 +1,72 @@
+package signals
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Settings is the settings for the signals service.
+type Settings struct {
+	// Name is the name of the service.
+	// It is used for the service `String` method.
+	// It defaults to the empty string.
+	Name *string
+	// Terminate are the signals triggering a run error wrapping
+	// `goservices.ErrSignalReceived`, so a parent service management
+	// type such as a group stops all its services.
+	// It defaults to SIGINT and SIGTERM if left unset, and can
+	// be set to an empty slice to disable terminating signals.
+	Terminate []os.Signal
+	// Callbacks maps signals to callback functions called when
+	// the signal is received, for example to reload configuration
+	// on SIGHUP. Callbacks are called sequentially in the same
+	// goroutine, and `Stop` waits for a callback in progress to return.
+	// It defaults to an empty map.
+	Callbacks map[os.Signal]func(signal os.Signal)
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Name == nil {
+		s.Name = new(string)
+	}
+
+	if s.Terminate == nil {
+		s.Terminate = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	}
+
+	if s.Callbacks == nil {
+		s.Callbacks = map[os.Signal]func(os.Signal){}
+	}
+}
+
+var (
+	ErrNoSignal       = errors.New("no signal specified")
+	ErrSignalConflict = errors.New("signal is both terminating and has a callback")
+	ErrCallbackIsNil  = errors.New("callback is nil")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	if len(s.Terminate) == 0 && len(s.Callbacks) == 0 {
+		return fmt.Errorf("%w", ErrNoSignal)
+	}
+
+	for _, terminateSignal := range s.Terminate {
+		_, ok := s.Callbacks[terminateSignal]
+		if ok {
+			return fmt.Errorf("%w: %s", ErrSignalConflict, terminateSignal)
+		}
+	}
+
+	for callbackSignal, callback := range s.Callbacks {
+		if callback == nil {
+			return fmt.Errorf("%w: for signal %s", ErrCallbackIsNil, callbackSignal)
+		}
+	}
+
+	return nil
+}
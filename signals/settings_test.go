@@ -0,0 +1,66 @@
+package signals
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Settings_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings   Settings
+		errWrapped error
+		errMessage string
+	}{
+		"no_signal": {
+			settings:   Settings{Terminate: []os.Signal{}},
+			errWrapped: ErrNoSignal,
+			errMessage: "no signal specified",
+		},
+		"signal_conflict": {
+			settings: Settings{
+				Terminate: []os.Signal{syscall.SIGTERM},
+				Callbacks: map[os.Signal]func(os.Signal){
+					syscall.SIGTERM: func(os.Signal) {},
+				},
+			},
+			errWrapped: ErrSignalConflict,
+			errMessage: "signal is both terminating and has a callback: terminated",
+		},
+		"nil_callback": {
+			settings: Settings{
+				Callbacks: map[os.Signal]func(os.Signal){
+					syscall.SIGHUP: nil,
+				},
+			},
+			errWrapped: ErrCallbackIsNil,
+			errMessage: "callback is nil: for signal hangup",
+		},
+		"valid": {
+			settings: Settings{
+				Terminate: []os.Signal{syscall.SIGTERM},
+				Callbacks: map[os.Signal]func(os.Signal){
+					syscall.SIGHUP: func(os.Signal) {},
+				},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.settings.Validate()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
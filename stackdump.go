@@ -0,0 +1,77 @@
+package goservices
+
+import (
+	"bytes"
+	"regexp"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+)
+
+// goroutinesDump returns the stacks of goroutines labeled with the
+// service path given or a service path nested under it, in the
+// pprof goroutine profile debug text format.
+// If no goroutine is labeled for the service, the stacks of all
+// goroutines are returned in the `runtime.Stack` format instead.
+func goroutinesDump(servicePath string) (dump []byte) {
+	buffer := bytes.NewBuffer(nil)
+	const debugLevel = 1 // aggregated stacks with labels
+	_ = pprof.Lookup("goroutine").WriteTo(buffer, debugLevel)
+	dump = filterGoroutineProfile(buffer.Bytes(), servicePath)
+	if len(dump) > 0 {
+		return dump
+	}
+	return allGoroutinesStack()
+}
+
+func allGoroutinesStack() (stack []byte) {
+	const initialSize = 64 * 1024
+	stack = make([]byte, initialSize)
+	for {
+		n := runtime.Stack(stack, true)
+		if n < len(stack) {
+			return stack[:n]
+		}
+		stack = make([]byte, 2*len(stack)) //nolint:mnd
+	}
+}
+
+var regexServiceLabel = regexp.MustCompile(
+	`"` + regexp.QuoteMeta(ProfilerLabelService) + `":("(?:[^"\\]|\\.)*")`)
+
+// filterGoroutineProfile filters the goroutine profile given,
+// in its debug level 1 text format, to keep only the stack records
+// labeled with the service path given or a service path nested
+// under it. It returns nil if no record matches.
+func filterGoroutineProfile(profile []byte, servicePath string) (filtered []byte) {
+	records := strings.Split(string(profile), "\n\n")
+	var matching []string
+	for _, record := range records {
+		for line := range strings.SplitSeq(record, "\n") {
+			if !strings.HasPrefix(line, "# labels: ") {
+				continue
+			}
+			match := regexServiceLabel.FindStringSubmatch(line)
+			if match == nil {
+				break
+			}
+			path, err := strconv.Unquote(match[1])
+			if err == nil && (path == servicePath ||
+				strings.HasPrefix(path, servicePath+"/")) {
+				matching = append(matching, strings.TrimSpace(record))
+			}
+			break
+		}
+	}
+
+	if len(matching) == 0 {
+		return nil
+	}
+	// Remove the profile header line from the first record if needed.
+	first, rest, found := strings.Cut(matching[0], "\n")
+	if found && strings.HasPrefix(first, "goroutine profile:") {
+		matching[0] = rest
+	}
+	return []byte(strings.Join(matching, "\n\n") + "\n")
+}
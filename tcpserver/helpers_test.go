@@ -0,0 +1,3 @@
+package tcpserver
+
+func stringPtr(s string) *string { return &s }
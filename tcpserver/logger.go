@@ -0,0 +1,11 @@
+package tcpserver
+
+// Infoer is the logging interface required by the
+// TCP server service implementation.
+type Infoer interface {
+	Info(message string)
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Info(_ string) {}
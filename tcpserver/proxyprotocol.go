@@ -0,0 +1,191 @@
+package tcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrProxyHeaderMissing  = errors.New("PROXY protocol header is missing")
+	ErrProxyHeaderNotValid = errors.New("PROXY protocol header is not valid")
+)
+
+const (
+	proxyV1Prefix = "PROXY "
+	// proxyV1MaxLength is the maximum length of a version 1
+	// header, including the CRLF line ending.
+	proxyV1MaxLength = 107
+	// proxyV2HeaderLength is the length of the fixed part of a
+	// version 2 header, before the addresses and TLVs.
+	proxyV2HeaderLength = 16
+)
+
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n") //nolint:gochecknoglobals
+
+// proxyConn is a connection with its remote and local addresses
+// set from a PROXY protocol header. It reads from a buffered reader
+// to not lose data read after the header.
+type proxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+	remote net.Addr
+	local  net.Addr
+}
+
+func (c *proxyConn) Read(b []byte) (n int, err error) { return c.reader.Read(b) }
+func (c *proxyConn) RemoteAddr() net.Addr             { return c.remote }
+func (c *proxyConn) LocalAddr() net.Addr              { return c.local }
+
+// readProxyHeader reads a PROXY protocol version 1 or 2 header
+// from the connection, and returns a connection with the addresses
+// from the header. Headers for an unknown or local connection, such
+// as load balancer health checks, keep the original addresses.
+func readProxyHeader(connection net.Conn) (proxied net.Conn, err error) {
+	reader := bufio.NewReader(connection)
+	conn := &proxyConn{
+		Conn:   connection,
+		reader: reader,
+		remote: connection.RemoteAddr(),
+		local:  connection.LocalAddr(),
+	}
+
+	signature, err := reader.Peek(len(proxyV2Signature))
+	switch {
+	case err != nil && len(signature) < len(proxyV1Prefix):
+		return nil, fmt.Errorf("%w: %w", ErrProxyHeaderMissing, err)
+	case bytes.HasPrefix(signature, []byte(proxyV1Prefix)):
+		err = readProxyV1(reader, conn)
+	case bytes.Equal(signature, proxyV2Signature):
+		err = readProxyV2(reader, conn)
+	default:
+		return nil, fmt.Errorf("%w", ErrProxyHeaderMissing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// readProxyV1 reads a version 1 header such as
+// "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n".
+func readProxyV1(reader *bufio.Reader, conn *proxyConn) (err error) {
+	line, err := reader.ReadSlice('\n')
+	switch {
+	case errors.Is(err, bufio.ErrBufferFull) || len(line) > proxyV1MaxLength:
+		return fmt.Errorf("%w: version 1 header exceeds %d bytes",
+			ErrProxyHeaderNotValid, proxyV1MaxLength)
+	case err != nil:
+		return fmt.Errorf("reading version 1 header: %w", err)
+	case !bytes.HasSuffix(line, []byte("\r\n")):
+		return fmt.Errorf("%w: version 1 header does not end with CRLF", ErrProxyHeaderNotValid)
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil
+	}
+
+	const expectedFields = 6
+	if len(fields) != expectedFields {
+		return fmt.Errorf("%w: version 1 header has %d fields instead of %d",
+			ErrProxyHeaderNotValid, len(fields), expectedFields)
+	}
+
+	switch fields[1] {
+	case "TCP4", "TCP6":
+	default:
+		return fmt.Errorf("%w: version 1 protocol %q", ErrProxyHeaderNotValid, fields[1])
+	}
+
+	conn.remote, err = parseProxyV1Address(fields[2], fields[4])
+	if err != nil {
+		return fmt.Errorf("source address: %w", err)
+	}
+	conn.local, err = parseProxyV1Address(fields[3], fields[5])
+	if err != nil {
+		return fmt.Errorf("destination address: %w", err)
+	}
+	return nil
+}
+
+func parseProxyV1Address(ipString, portString string) (address *net.TCPAddr, err error) {
+	ip := net.ParseIP(ipString)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: IP address %q", ErrProxyHeaderNotValid, ipString)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: port %q", ErrProxyHeaderNotValid, portString)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2 reads a binary version 2 header.
+func readProxyV2(reader *bufio.Reader, conn *proxyConn) (err error) {
+	header := make([]byte, proxyV2HeaderLength)
+	_, err = io.ReadFull(reader, header)
+	if err != nil {
+		return fmt.Errorf("reading version 2 header: %w", err)
+	}
+
+	versionCommand, family := header[12], header[13]
+	const version2 = 0x2
+	if versionCommand>>4 != version2 {
+		return fmt.Errorf("%w: version %d", ErrProxyHeaderNotValid, versionCommand>>4)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	_, err = io.ReadFull(reader, payload)
+	if err != nil {
+		return fmt.Errorf("reading version 2 addresses: %w", err)
+	}
+
+	const (
+		commandLocal = 0x0
+		commandProxy = 0x1
+	)
+	switch versionCommand & 0x0f {
+	case commandLocal:
+		return nil
+	case commandProxy:
+	default:
+		return fmt.Errorf("%w: command %d", ErrProxyHeaderNotValid, versionCommand&0x0f)
+	}
+
+	var ipLength int
+	const (
+		familyInet  = 0x1
+		familyInet6 = 0x2
+	)
+	switch family >> 4 {
+	case familyInet:
+		ipLength = net.IPv4len
+	case familyInet6:
+		ipLength = net.IPv6len
+	default:
+		// unspecified or unix addresses
+		return nil
+	}
+
+	const portsLength = 4
+	if len(payload) < 2*ipLength+portsLength {
+		return fmt.Errorf("%w: addresses length %d is too short",
+			ErrProxyHeaderNotValid, len(payload))
+	}
+	conn.remote = &net.TCPAddr{
+		IP:   net.IP(payload[:ipLength]),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLength:])),
+	}
+	conn.local = &net.TCPAddr{
+		IP:   net.IP(payload[ipLength : 2*ipLength]),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLength+2:])),
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package tcpserver
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_readProxyHeader(t *testing.T) {
+	t.Parallel()
+
+	v2Header := func(versionCommand, family byte, addresses ...byte) []byte {
+		header := append([]byte{}, proxyV2Signature...)
+		header = append(header, versionCommand, family, 0, byte(len(addresses)))
+		return append(header, addresses...)
+	}
+
+	testCases := map[string]struct {
+		data          []byte
+		remoteAddress string
+		localAddress  string
+		errWrapped    error
+		errMessage    string
+	}{
+		"v1_tcp4": {
+			data:          []byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"),
+			remoteAddress: "192.0.2.1:56324",
+			localAddress:  "198.51.100.1:443",
+		},
+		"v1_tcp6": {
+			data:          []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56324 443\r\n"),
+			remoteAddress: "[2001:db8::1]:56324",
+			localAddress:  "[2001:db8::2]:443",
+		},
+		"v1_unknown": {
+			data:          []byte("PROXY UNKNOWN\r\n"),
+			remoteAddress: "pipe",
+			localAddress:  "pipe",
+		},
+		"v1_bad_port": {
+			data:       []byte("PROXY TCP4 192.0.2.1 198.51.100.1 x 443\r\n"),
+			errWrapped: ErrProxyHeaderNotValid,
+			errMessage: `source address: PROXY protocol header is not valid: port "x"`,
+		},
+		"v1_missing_fields": {
+			data:       []byte("PROXY TCP4 192.0.2.1\r\n"),
+			errWrapped: ErrProxyHeaderNotValid,
+			errMessage: "PROXY protocol header is not valid: version 1 header has 3 fields instead of 6",
+		},
+		"v2_tcp4": {
+			data: v2Header(0x21, 0x11,
+				192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb),
+			remoteAddress: "192.0.2.1:56324",
+			localAddress:  "198.51.100.1:443",
+		},
+		"v2_local": {
+			data:          v2Header(0x20, 0x00),
+			remoteAddress: "pipe",
+			localAddress:  "pipe",
+		},
+		"v2_short_addresses": {
+			data:       v2Header(0x21, 0x11, 192, 0, 2, 1),
+			errWrapped: ErrProxyHeaderNotValid,
+			errMessage: "PROXY protocol header is not valid: addresses length 4 is too short",
+		},
+		"v2_bad_version": {
+			data:       v2Header(0x11, 0x11),
+			errWrapped: ErrProxyHeaderNotValid,
+			errMessage: "PROXY protocol header is not valid: version 1",
+		},
+		"missing_header": {
+			data:       []byte("GET / HTTP/1.1\r\n\r\n"),
+			errWrapped: ErrProxyHeaderMissing,
+			errMessage: "PROXY protocol header is missing",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server, client := net.Pipe()
+			t.Cleanup(func() {
+				_ = server.Close()
+				_ = client.Close()
+			})
+			go func() {
+				_, _ = client.Write(append(testCase.data, []byte("payload")...))
+			}()
+
+			proxied, err := readProxyHeader(server)
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+				return
+			}
+			assert.Equal(t, testCase.remoteAddress, proxied.RemoteAddr().String())
+			assert.Equal(t, testCase.localAddress, proxied.LocalAddr().String())
+
+			payload := make([]byte, len("payload"))
+			_, err = io.ReadFull(proxied, payload)
+			require.NoError(t, err)
+			assert.Equal(t, "payload", string(payload))
+		})
+	}
+}
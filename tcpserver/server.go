@@ -0,0 +1,302 @@
+// Package tcpserver implements a TCP server handing
+// each connection accepted to a connection handler.
+package tcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Service = (*Server)(nil)
+
+// ErrDrainTimeout is wrapped by the error returned by `Stop` when
+// connections are forcibly closed after the drain timeout.
+var ErrDrainTimeout = errors.New("drain timeout exceeded")
+
+// Server is a TCP server implementation.
+type Server struct {
+	// Dependencies injected
+	settings Settings
+
+	// Internal fields
+	startStopMutex   sync.Mutex
+	state            goservices.State
+	stateMutex       sync.RWMutex
+	listener         net.Listener
+	address          string
+	addressMutex     sync.RWMutex
+	slots            chan struct{}
+	handlersCtx      context.Context //nolint:containedctx
+	handlersCancel   context.CancelFunc
+	handlersDone     sync.WaitGroup
+	connections      map[net.Conn]struct{}
+	connectionsMutex sync.Mutex
+	acceptDone       chan struct{}
+}
+
+// New creates a new TCP server using the settings given.
+func New(settings Settings) (server *Server, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &Server{
+		settings: settings,
+		state:    goservices.StateStopped,
+	}, nil
+}
+
+func (s *Server) String() string {
+	if *s.settings.Name == "" {
+		return "tcp server"
+	}
+	return *s.settings.Name + " tcp server"
+}
+
+// GetAddress obtains the address the TCP server is listening on.
+// It is only set after `Start` returns without error.
+func (s *Server) GetAddress() (address string) {
+	s.addressMutex.RLock()
+	defer s.addressMutex.RUnlock()
+	return s.address
+}
+
+// Connections returns the number of connections currently
+// handled by the server.
+func (s *Server) Connections() (count int) {
+	s.connectionsMutex.Lock()
+	defer s.connectionsMutex.Unlock()
+	return len(s.connections)
+}
+
+// Start starts the TCP server service, listening on the
+// address from the settings using the context to cancel listening.
+func (s *Server) Start(ctx context.Context) (runError <-chan error, err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.RLock()
+	state := s.state
+	s.stateMutex.RUnlock()
+	if state == goservices.StateRunning {
+		return nil, fmt.Errorf("%s: %w", s, goservices.ErrAlreadyStarted)
+	}
+
+	s.state = goservices.StateStarting
+
+	listenConfig := net.ListenConfig{}
+	s.listener, err = listenConfig.Listen(ctx, "tcp", *s.settings.Address)
+	if err != nil {
+		s.state = goservices.StateStopped
+		return nil, err
+	}
+
+	s.addressMutex.Lock()
+	s.address = s.listener.Addr().String()
+	s.addressMutex.Unlock()
+	s.settings.Logger.Info(fmt.Sprintf("%s listening on %s", s, s.address))
+
+	s.slots = nil
+	if s.settings.MaxConnections > 0 {
+		s.slots = make(chan struct{}, s.settings.MaxConnections)
+	}
+	s.handlersCtx, s.handlersCancel = context.WithCancel(context.Background())
+	s.connectionsMutex.Lock()
+	s.connections = make(map[net.Conn]struct{})
+	s.connectionsMutex.Unlock()
+
+	runErrorBiDirectional := make(chan error)
+	s.acceptDone = make(chan struct{})
+	ready := make(chan struct{})
+
+	// Hold the state mutex locked in case accepting
+	// a connection fails instantly.
+	s.stateMutex.Lock()
+	go s.accept(ready, runErrorBiDirectional)
+	<-ready
+	s.state = goservices.StateRunning
+	s.stateMutex.Unlock()
+
+	return runErrorBiDirectional, nil
+}
+
+func (s *Server) accept(ready chan<- struct{}, runError chan<- error) {
+	close(ready)
+	// Copy fields set at Start, which may be set again
+	// at the next Start whilst handlers are still running.
+	slots, ctx := s.slots, s.handlersCtx
+	releaseSlot := func() {
+		if slots != nil {
+			<-slots
+		}
+	}
+
+	var retryDelay time.Duration
+	for {
+		if slots != nil {
+			// Wait for a connection slot to be available.
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				close(s.acceptDone)
+				return
+			}
+		}
+
+		connection, err := s.listener.Accept()
+		if err != nil && isTemporaryAcceptError(err) {
+			releaseSlot()
+			// Retry with an exponential backoff, as net/http does.
+			const minRetryDelay, maxRetryDelay = 5 * time.Millisecond, time.Second
+			retryDelay = min(max(2*retryDelay, minRetryDelay), maxRetryDelay)
+			s.settings.Logger.Info(fmt.Sprintf("%s: accepting connection: %s; retrying in %s",
+				s, err, retryDelay))
+			timer := time.NewTimer(retryDelay)
+			select {
+			case <-timer.C:
+				continue
+			case <-ctx.Done():
+				timer.Stop()
+				close(s.acceptDone)
+				return
+			}
+		} else if err != nil {
+			releaseSlot()
+			s.stateMutex.Lock()
+			if s.state == goservices.StateStopping {
+				s.stateMutex.Unlock()
+				close(s.acceptDone)
+				return
+			}
+			s.state = goservices.StateCrashed
+			s.stateMutex.Unlock()
+			close(s.acceptDone)
+			runError <- fmt.Errorf("accepting connection: %w", err)
+			return
+		}
+		retryDelay = 0
+
+		s.connectionsMutex.Lock()
+		s.connections[connection] = struct{}{}
+		s.connectionsMutex.Unlock()
+		s.handlersDone.Go(func() {
+			defer releaseSlot()
+			defer s.closeConnection(connection)
+			s.handle(ctx, connection)
+		})
+	}
+}
+
+// isTemporaryAcceptError returns true if the error returned by
+// accepting a connection is temporary, such as when running out
+// of file descriptors, in which case accepting can be retried.
+func isTemporaryAcceptError(err error) bool {
+	return errors.Is(err, syscall.EMFILE) ||
+		errors.Is(err, syscall.ENFILE) ||
+		errors.Is(err, syscall.ENOBUFS) ||
+		errors.Is(err, syscall.ENOMEM) ||
+		errors.Is(err, syscall.ECONNABORTED)
+}
+
+func (s *Server) handle(ctx context.Context, connection net.Conn) {
+	if s.settings.ProxyProtocol {
+		remoteAddress := connection.RemoteAddr()
+		_ = connection.SetReadDeadline(time.Now().Add(s.settings.ProxyHeaderTimeout))
+		proxied, err := readProxyHeader(connection)
+		if err != nil {
+			s.settings.Logger.Info(fmt.Sprintf("%s: rejecting connection from %s: %s",
+				s, remoteAddress, err))
+			return
+		}
+		_ = connection.SetReadDeadline(time.Time{})
+		connection = proxied
+	}
+	s.settings.Handler(ctx, connection)
+}
+
+func (s *Server) closeConnection(connection net.Conn) {
+	_ = connection.Close()
+	s.connectionsMutex.Lock()
+	delete(s.connections, connection)
+	s.connectionsMutex.Unlock()
+}
+
+// Stop stops the TCP server service. It stops accepting connections,
+// cancels the context of connection handlers and waits for them to
+// return, for at most the drain timeout. Connections still open at the
+// end of the timeout are forcibly closed, and an error wrapping
+// `ErrDrainTimeout` with the number of connections closed is returned.
+func (s *Server) Stop() (err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.Lock()
+	switch s.state {
+	case goservices.StateRunning: // continue stopping the server
+	case goservices.StateCrashed: // server stopped accepting connections
+		s.state = goservices.StateStopped
+		s.stateMutex.Unlock()
+		_ = s.listener.Close()
+		s.handlersCancel()
+		<-s.acceptDone
+		_ = s.drain()
+		return nil
+	case goservices.StateStopped:
+		s.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", s, goservices.ErrAlreadyStopped)
+	case goservices.StateStarting, goservices.StateStopping:
+		s.stateMutex.Unlock()
+		panic("bad implementation code: this code path should be unreachable")
+	}
+	s.state = goservices.StateStopping
+	s.stateMutex.Unlock()
+
+	// Stop accepting connections before draining handlers.
+	_ = s.listener.Close()
+	s.handlersCancel()
+	<-s.acceptDone
+	err = s.drain()
+
+	s.stateMutex.Lock()
+	s.state = goservices.StateStopped
+	s.stateMutex.Unlock()
+	return err
+}
+
+// drain waits for handlers to return for at most the drain timeout,
+// after which it forcibly closes remaining connections.
+// It must be called once the listener is closed, the handlers
+// context is canceled and the accept goroutine has returned.
+func (s *Server) drain() (err error) {
+	handlersDone := make(chan struct{})
+	go func() {
+		s.handlersDone.Wait()
+		close(handlersDone)
+	}()
+
+	timer := time.NewTimer(s.settings.DrainTimeout)
+	select {
+	case <-handlersDone:
+		timer.Stop()
+		return nil
+	case <-timer.C:
+	}
+
+	s.connectionsMutex.Lock()
+	closed := len(s.connections)
+	for connection := range s.connections {
+		_ = connection.Close()
+	}
+	s.connectionsMutex.Unlock()
+	return fmt.Errorf("%w after %s: %d connections forcibly closed",
+		ErrDrainTimeout, s.settings.DrainTimeout, closed)
+}
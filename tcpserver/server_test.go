@@ -0,0 +1,254 @@
+package tcpserver
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoHandler echoes data received back to the client,
+// until its connection is closed or its context is canceled.
+func echoHandler(ctx context.Context, connection net.Conn) {
+	stop := context.AfterFunc(ctx, func() { _ = connection.Close() })
+	defer stop()
+	_, _ = io.Copy(connection, connection)
+}
+
+func dial(t *testing.T, address string) net.Conn {
+	t.Helper()
+	connection, err := net.DialTimeout("tcp", address, time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = connection.Close() })
+	_ = connection.SetDeadline(time.Now().Add(5 * time.Second))
+	return connection
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(Settings{})
+	assert.EqualError(t, err, "validating settings: handler is nil")
+
+	server, err := New(Settings{Handler: echoHandler, Name: stringPtr("dns")})
+	require.NoError(t, err)
+	assert.Equal(t, "dns tcp server", server.String())
+}
+
+func Test_Server_echo(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Settings{
+		Handler: echoHandler,
+		Address: stringPtr("127.0.0.1:0"),
+	})
+	require.NoError(t, err)
+
+	runError, err := server.Start(context.Background())
+	require.NoError(t, err)
+
+	connection := dial(t, server.GetAddress())
+	_, err = connection.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	line, err := bufio.NewReader(connection).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", line)
+	assert.Equal(t, 1, server.Connections())
+
+	err = server.Stop()
+	require.NoError(t, err)
+	assert.Zero(t, server.Connections())
+
+	select {
+	case err := <-runError:
+		t.Fatalf("unexpected run error: %s", err)
+	default:
+	}
+}
+
+func Test_Server_Stop_cancelsHandlers(t *testing.T) {
+	t.Parallel()
+
+	handlerDone := make(chan struct{})
+	server, err := New(Settings{
+		Handler: func(ctx context.Context, _ net.Conn) {
+			<-ctx.Done()
+			close(handlerDone)
+		},
+		Address: stringPtr("127.0.0.1:0"),
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+
+	_ = dial(t, server.GetAddress())
+	require.Eventually(t, func() bool {
+		return server.Connections() == 1
+	}, time.Second, time.Millisecond)
+
+	err = server.Stop()
+	require.NoError(t, err)
+	<-handlerDone
+
+	err = server.Stop()
+	assert.ErrorIs(t, err, goservices.ErrAlreadyStopped)
+}
+
+func Test_Server_Stop_drainTimeout(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Settings{
+		Handler: func(_ context.Context, connection net.Conn) {
+			// Ignore the context cancellation.
+			_, _ = io.Copy(connection, connection)
+		},
+		Address:      stringPtr("127.0.0.1:0"),
+		DrainTimeout: 20 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+
+	_ = dial(t, server.GetAddress())
+	require.Eventually(t, func() bool {
+		return server.Connections() == 1
+	}, time.Second, time.Millisecond)
+
+	err = server.Stop()
+	assert.ErrorIs(t, err, ErrDrainTimeout)
+	assert.EqualError(t, err, "drain timeout exceeded after 20ms: 1 connections forcibly closed")
+}
+
+func Test_Server_maxConnections(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	server, err := New(Settings{
+		Handler: func(_ context.Context, connection net.Conn) {
+			<-release
+			_, _ = connection.Write([]byte("done\n"))
+		},
+		Address:        stringPtr("127.0.0.1:0"),
+		MaxConnections: 1,
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+
+	first := dial(t, server.GetAddress())
+	require.Eventually(t, func() bool {
+		return server.Connections() == 1
+	}, time.Second, time.Millisecond)
+
+	// The second connection is queued by the kernel
+	// but not accepted until the first one is handled.
+	second := dial(t, server.GetAddress())
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, server.Connections())
+
+	close(release)
+	for _, connection := range []net.Conn{first, second} {
+		line, err := bufio.NewReader(connection).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "done\n", line)
+	}
+
+	err = server.Stop()
+	require.NoError(t, err)
+}
+
+func Test_Server_acceptError(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Settings{
+		Handler: echoHandler,
+		Address: stringPtr("127.0.0.1:0"),
+	})
+	require.NoError(t, err)
+
+	runError, err := server.Start(context.Background())
+	require.NoError(t, err)
+
+	_ = server.listener.Close()
+	err = <-runError
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	err = server.Stop()
+	require.NoError(t, err)
+}
+
+func Test_isTemporaryAcceptError(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		err       error
+		temporary bool
+	}{
+		"listener_closed": {
+			err: &net.OpError{Op: "accept", Err: net.ErrClosed},
+		},
+		"too_many_open_files": {
+			err:       &net.OpError{Op: "accept", Err: os.NewSyscallError("accept", syscall.EMFILE)},
+			temporary: true,
+		},
+		"connection_aborted": {
+			err:       &net.OpError{Op: "accept", Err: os.NewSyscallError("accept", syscall.ECONNABORTED)},
+			temporary: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			temporary := isTemporaryAcceptError(testCase.err)
+			assert.Equal(t, testCase.temporary, temporary)
+		})
+	}
+}
+
+func Test_Server_proxyProtocol(t *testing.T) {
+	t.Parallel()
+
+	remoteAddresses := make(chan string, 1)
+	server, err := New(Settings{
+		Handler: func(ctx context.Context, connection net.Conn) {
+			remoteAddresses <- connection.RemoteAddr().String()
+			echoHandler(ctx, connection)
+		},
+		Address:       stringPtr("127.0.0.1:0"),
+		ProxyProtocol: true,
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+
+	connection := dial(t, server.GetAddress())
+	_, err = connection.Write([]byte("PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nhello\n"))
+	require.NoError(t, err)
+	line, err := bufio.NewReader(connection).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", line)
+	assert.Equal(t, "192.0.2.1:56324", <-remoteAddresses)
+
+	// A connection without header is closed.
+	connection = dial(t, server.GetAddress())
+	_, err = connection.Write([]byte("GET / HTTP/1.1\r\n\r\n"))
+	require.NoError(t, err)
+	_, err = connection.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+
+	err = server.Stop()
+	require.NoError(t, err)
+}
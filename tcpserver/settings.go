@@ -0,0 +1,115 @@
+package tcpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Handler handles a connection accepted by the TCP server.
+// The context is canceled when the server stops, and the
+// connection is closed by the server once the handler returns.
+type Handler func(ctx context.Context, connection net.Conn)
+
+// Settings is the settings for the TCP server service.
+type Settings struct {
+	// Handler is the connection handler to use.
+	// It must be set for settings validation to pass.
+	Handler Handler
+	// Name is the name of the server.
+	// It is used for the server `String` method
+	// and for logs if a logger is set.
+	// It defaults to the empty string.
+	Name *string
+	// Address is the listening address to use.
+	// It defaults to the empty string (random port).
+	Address *string
+	// MaxConnections is the maximum number of connections handled
+	// concurrently. Once reached, the server stops accepting new
+	// connections until a connection handler returns.
+	// It defaults to 0, meaning there is no limit.
+	MaxConnections int
+	// ProxyProtocol, if true, requires each connection to start with
+	// a PROXY protocol version 1 or 2 header, for example when the
+	// server is behind a load balancer. The connection given to the
+	// handler then has the client and server addresses from the header
+	// as its remote and local addresses. Connections without a valid
+	// header are closed. It defaults to false.
+	ProxyProtocol bool
+	// ProxyHeaderTimeout is the maximum duration to read the PROXY
+	// protocol header of a connection, if `ProxyProtocol` is enabled.
+	// It defaults to 5 seconds.
+	ProxyHeaderTimeout time.Duration
+	// DrainTimeout is the maximum duration to wait for connection
+	// handlers to return when stopping the server, after their context
+	// is canceled. Once it expires, remaining connections are forcibly
+	// closed. It defaults to 3 seconds.
+	DrainTimeout time.Duration
+	// Logger is the logger to use to log on what address the
+	// server is listening and connections rejected because of
+	// their PROXY protocol header.
+	// It defaults to a no-op logger.
+	Logger Infoer
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Name == nil {
+		s.Name = new(string)
+	}
+
+	if s.Address == nil {
+		s.Address = new(string)
+	}
+
+	if s.ProxyHeaderTimeout == 0 {
+		const defaultProxyHeaderTimeout = 5 * time.Second
+		s.ProxyHeaderTimeout = defaultProxyHeaderTimeout
+	}
+
+	if s.DrainTimeout == 0 {
+		const defaultDrainTimeout = 3 * time.Second
+		s.DrainTimeout = defaultDrainTimeout
+	}
+
+	if s.Logger == nil {
+		s.Logger = new(noopLogger)
+	}
+}
+
+var (
+	ErrHandlerIsNil             = errors.New("handler is nil")
+	ErrListeningAddressNotValid = errors.New("listening address is not valid")
+	ErrMaxConnectionsNotValid   = errors.New("maximum connections is not valid")
+	ErrTimeoutNotValid          = errors.New("timeout is not valid")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	if s.Handler == nil {
+		return fmt.Errorf("%w", ErrHandlerIsNil)
+	}
+
+	if *s.Address != "" {
+		_, err = net.ResolveTCPAddr("tcp", *s.Address)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrListeningAddressNotValid, err)
+		}
+	}
+
+	if s.MaxConnections < 0 {
+		return fmt.Errorf("%w: %d", ErrMaxConnectionsNotValid, s.MaxConnections)
+	}
+
+	switch {
+	case s.ProxyHeaderTimeout < 0:
+		return fmt.Errorf("%w: proxy header timeout %s", ErrTimeoutNotValid, s.ProxyHeaderTimeout)
+	case s.DrainTimeout < 0:
+		return fmt.Errorf("%w: drain timeout %s", ErrTimeoutNotValid, s.DrainTimeout)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package tcpserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Settings_SetDefaults(t *testing.T) {
+	t.Parallel()
+
+	settings := Settings{}
+	settings.SetDefaults()
+
+	assert.Equal(t, Settings{
+		Name:               stringPtr(""),
+		Address:            stringPtr(""),
+		ProxyHeaderTimeout: 5 * time.Second,
+		DrainTimeout:       3 * time.Second,
+		Logger:             &noopLogger{},
+	}, settings)
+}
+
+func Test_Settings_Validate(t *testing.T) {
+	t.Parallel()
+
+	handler := func(context.Context, net.Conn) {}
+
+	testCases := map[string]struct {
+		settings   Settings
+		errWrapped error
+		errMessage string
+	}{
+		"nil handler": {
+			errWrapped: ErrHandlerIsNil,
+			errMessage: "handler is nil",
+		},
+		"invalid address": {
+			settings: Settings{
+				Handler: handler,
+				Address: stringPtr("127.0.0.1:-1"),
+			},
+			errWrapped: ErrListeningAddressNotValid,
+			errMessage: "listening address is not valid: address -1: invalid port",
+		},
+		"negative max connections": {
+			settings: Settings{
+				Handler:        handler,
+				Address:        stringPtr(""),
+				MaxConnections: -1,
+			},
+			errWrapped: ErrMaxConnectionsNotValid,
+			errMessage: "maximum connections is not valid: -1",
+		},
+		"negative drain timeout": {
+			settings: Settings{
+				Handler:      handler,
+				Address:      stringPtr(""),
+				DrainTimeout: -time.Second,
+			},
+			errWrapped: ErrTimeoutNotValid,
+			errMessage: "timeout is not valid: drain timeout -1s",
+		},
+		"valid settings": {
+			settings: Settings{
+				Handler: handler,
+				Address: stringPtr(":0"),
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.settings.Validate()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
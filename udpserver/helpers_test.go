@@ -0,0 +1,3 @@
+package udpserver
+
+func stringPtr(s string) *string { return &s }
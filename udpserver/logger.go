@@ -0,0 +1,11 @@
+package udpserver
+
+// Infoer is the logging interface required by the
+// UDP server service implementation.
+type Infoer interface {
+	Info(message string)
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Info(_ string) {}
@@ -0,0 +1,257 @@
+// Package udpserver implements a UDP server handing each
+// packet received to a handler through a pool of workers.
+package udpserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Service = (*Server)(nil)
+
+// ErrShutdownTimeout is wrapped by the error returned by `Stop`
+// when handlers do not complete within the shutdown timeout.
+var ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+
+// Server is a UDP server implementation.
+type Server struct {
+	// Dependencies injected
+	settings Settings
+
+	// Internal fields
+	startStopMutex sync.Mutex
+	state          goservices.State
+	stateMutex     sync.RWMutex
+	packetConn     net.PacketConn
+	address        string
+	addressMutex   sync.RWMutex
+	handlersCtx    context.Context //nolint:containedctx
+	handlersCancel context.CancelFunc
+	workersDone    *sync.WaitGroup
+	inFlight       *atomic.Int64
+	readStop       chan struct{}
+	readDone       chan struct{}
+}
+
+type packet struct {
+	data    []byte
+	address net.Addr
+}
+
+// New creates a new UDP server using the settings given.
+func New(settings Settings) (server *Server, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return &Server{
+		settings: settings,
+		state:    goservices.StateStopped,
+	}, nil
+}
+
+func (s *Server) String() string {
+	if *s.settings.Name == "" {
+		return "udp server"
+	}
+	return *s.settings.Name + " udp server"
+}
+
+// GetAddress obtains the address the UDP server is listening on.
+// It is only set after `Start` returns without error.
+func (s *Server) GetAddress() (address string) {
+	s.addressMutex.RLock()
+	defer s.addressMutex.RUnlock()
+	return s.address
+}
+
+// Start starts the UDP server service, binding the address from
+// the settings using the context to cancel binding, and starting
+// the workers handling packets received.
+func (s *Server) Start(ctx context.Context) (runError <-chan error, err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.RLock()
+	state := s.state
+	s.stateMutex.RUnlock()
+	if state == goservices.StateRunning {
+		return nil, fmt.Errorf("%s: %w", s, goservices.ErrAlreadyStarted)
+	}
+
+	s.state = goservices.StateStarting
+
+	listenConfig := net.ListenConfig{}
+	s.packetConn, err = listenConfig.ListenPacket(ctx, "udp", *s.settings.Address)
+	if err != nil {
+		s.state = goservices.StateStopped
+		return nil, err
+	}
+
+	s.addressMutex.Lock()
+	s.address = s.packetConn.LocalAddr().String()
+	s.addressMutex.Unlock()
+	s.settings.Logger.Info(fmt.Sprintf("%s listening on %s", s, s.address))
+
+	s.handlersCtx, s.handlersCancel = context.WithCancel(context.Background())
+	s.inFlight = new(atomic.Int64)
+	packets := make(chan packet, s.settings.QueueSize)
+	s.workersDone = new(sync.WaitGroup)
+	packetConn, handlersCtx, inFlight := s.packetConn, s.handlersCtx, s.inFlight
+	for range s.settings.Workers {
+		s.workersDone.Go(func() {
+			s.work(handlersCtx, packetConn, inFlight, packets) //nolint:contextcheck
+		})
+	}
+
+	runErrorBiDirectional := make(chan error)
+	s.readStop = make(chan struct{})
+	s.readDone = make(chan struct{})
+	ready := make(chan struct{})
+
+	// Hold the state mutex locked in case reading
+	// a packet fails instantly.
+	s.stateMutex.Lock()
+	go s.read(ready, packets, runErrorBiDirectional)
+	<-ready
+	s.state = goservices.StateRunning
+	s.stateMutex.Unlock()
+
+	return runErrorBiDirectional, nil
+}
+
+func (s *Server) read(ready chan<- struct{}, packets chan<- packet,
+	runError chan<- error) {
+	packetConn, readStop, readDone := s.packetConn, s.readStop, s.readDone
+	close(ready)
+	buffer := make([]byte, s.settings.MaxPacketSize)
+
+	for {
+		n, address, err := packetConn.ReadFrom(buffer)
+		if n > 0 {
+			select {
+			case packets <- packet{
+				data:    bytes.Clone(buffer[:n]),
+				address: address,
+			}:
+			case <-readStop:
+				// Drop the packet if the queue is full and the
+				// server is stopping, and stop on the read error
+				// from the read deadline set.
+			}
+		}
+		if err == nil {
+			continue
+		}
+
+		// Workers handle the packets queued and then return.
+		close(packets)
+		s.stateMutex.Lock()
+		if s.state == goservices.StateStopping {
+			s.stateMutex.Unlock()
+			close(readDone)
+			return
+		}
+		s.state = goservices.StateCrashed
+		s.stateMutex.Unlock()
+		close(readDone)
+		runError <- fmt.Errorf("reading packet: %w", err)
+		return
+	}
+}
+
+func (s *Server) work(ctx context.Context, packetConn net.PacketConn,
+	inFlight *atomic.Int64, packets <-chan packet) {
+	for packet := range packets {
+		inFlight.Add(1)
+		s.settings.Handler(ctx, packetConn, packet.data, packet.address)
+		inFlight.Add(-1)
+	}
+}
+
+// Stop stops the UDP server service. It stops reading packets, waits
+// for the handlers to handle packets already received for at most the
+// shutdown timeout, and then closes the socket. Note the socket is
+// closed after the handlers return rather than before, so handlers
+// can still send responses on it. If the timeout expires, the
+// handlers context is canceled and an error wrapping
+// `ErrShutdownTimeout` with the number of handlers still running
+// is returned.
+func (s *Server) Stop() (err error) {
+	s.startStopMutex.Lock()
+	defer s.startStopMutex.Unlock()
+
+	s.stateMutex.Lock()
+	switch s.state {
+	case goservices.StateRunning: // continue stopping the server
+	case goservices.StateCrashed: // server stopped reading packets
+		s.state = goservices.StateStopped
+		s.stateMutex.Unlock()
+		<-s.readDone
+		_ = s.waitHandlers()
+		_ = s.packetConn.Close()
+		return nil
+	case goservices.StateStopped:
+		s.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", s, goservices.ErrAlreadyStopped)
+	case goservices.StateStarting, goservices.StateStopping:
+		s.stateMutex.Unlock()
+		panic("bad implementation code: this code path should be unreachable")
+	}
+	s.state = goservices.StateStopping
+	s.stateMutex.Unlock()
+
+	// Unblock reading without closing the socket,
+	// so handlers can still send responses.
+	close(s.readStop)
+	_ = s.packetConn.SetReadDeadline(time.Unix(1, 0))
+	<-s.readDone
+	err = s.waitHandlers()
+	closeErr := s.packetConn.Close()
+	if closeErr != nil {
+		closeErr = fmt.Errorf("closing socket: %w", closeErr)
+		if err == nil {
+			err = closeErr
+		} else {
+			err = fmt.Errorf("%w (and %w)", err, closeErr)
+		}
+	}
+
+	s.stateMutex.Lock()
+	s.state = goservices.StateStopped
+	s.stateMutex.Unlock()
+	return err
+}
+
+// waitHandlers waits for the workers to handle the packets queued
+// for at most the shutdown timeout, after which it cancels the
+// handlers context and returns an error.
+func (s *Server) waitHandlers() (err error) {
+	defer s.handlersCancel()
+
+	workersDone := make(chan struct{})
+	go func() {
+		s.workersDone.Wait()
+		close(workersDone)
+	}()
+
+	timer := time.NewTimer(s.settings.ShutdownTimeout)
+	select {
+	case <-workersDone:
+		timer.Stop()
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w after %s: %d handlers still running",
+			ErrShutdownTimeout, s.settings.ShutdownTimeout, s.inFlight.Load())
+	}
+}
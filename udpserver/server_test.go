@@ -0,0 +1,196 @@
+package udpserver
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func echoHandler(_ context.Context, writer PacketWriter,
+	packet []byte, address net.Addr) {
+	_, _ = writer.WriteTo(packet, address)
+}
+
+func dial(t *testing.T, address string) net.Conn {
+	t.Helper()
+	connection, err := net.Dial("udp", address)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = connection.Close() })
+	_ = connection.SetDeadline(time.Now().Add(5 * time.Second))
+	return connection
+}
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(Settings{})
+	assert.EqualError(t, err, "validating settings: handler is nil")
+
+	server, err := New(Settings{Handler: echoHandler, Name: stringPtr("dns")})
+	require.NoError(t, err)
+	assert.Equal(t, "dns udp server", server.String())
+}
+
+func Test_Server_echo(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Settings{
+		Handler: echoHandler,
+		Address: stringPtr("127.0.0.1:0"),
+	})
+	require.NoError(t, err)
+
+	runError, err := server.Start(context.Background())
+	require.NoError(t, err)
+
+	connection := dial(t, server.GetAddress())
+	_, err = connection.Write([]byte("hello"))
+	require.NoError(t, err)
+	response := make([]byte, 16)
+	n, err := connection.Read(response)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(response[:n]))
+
+	err = server.Stop()
+	require.NoError(t, err)
+	select {
+	case err := <-runError:
+		t.Fatalf("unexpected run error: %s", err)
+	default:
+	}
+
+	err = server.Stop()
+	assert.ErrorIs(t, err, goservices.ErrAlreadyStopped)
+}
+
+func Test_Server_workers(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int32
+	server, err := New(Settings{
+		Handler: func(ctx context.Context, writer PacketWriter,
+			packet []byte, address net.Addr) {
+			current := running.Add(1)
+			defer running.Add(-1)
+			if current > maxRunning.Load() {
+				maxRunning.Store(current)
+			}
+			<-release
+			echoHandler(ctx, writer, packet, address)
+		},
+		Address:   stringPtr("127.0.0.1:0"),
+		Workers:   2,
+		QueueSize: 4,
+	})
+	require.NoError(t, err)
+
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+
+	connection := dial(t, server.GetAddress())
+	const packets = 5
+	for range packets {
+		_, err = connection.Write([]byte("x"))
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return running.Load() == 2
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	for range packets {
+		_, err = connection.Read(make([]byte, 1))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), maxRunning.Load())
+
+	err = server.Stop()
+	require.NoError(t, err)
+}
+
+func Test_Server_Stop_waitsHandlers(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		handlerStuck bool
+		errMessage   string
+	}{
+		"handler_completes": {},
+		"handler_stuck": {
+			handlerStuck: true,
+			errMessage:   "shutdown timeout exceeded after 50ms: 1 handlers still running",
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			handling := make(chan struct{})
+			handlerCtxDone := make(chan struct{})
+			server, err := New(Settings{
+				Handler: func(ctx context.Context, writer PacketWriter,
+					packet []byte, address net.Addr) {
+					close(handling)
+					if testCase.handlerStuck {
+						<-ctx.Done()
+						close(handlerCtxDone)
+						return
+					}
+					time.Sleep(10 * time.Millisecond)
+					// The socket is still open to respond.
+					echoHandler(ctx, writer, packet, address)
+				},
+				Address:         stringPtr("127.0.0.1:0"),
+				ShutdownTimeout: 50 * time.Millisecond,
+			})
+			require.NoError(t, err)
+
+			_, err = server.Start(context.Background())
+			require.NoError(t, err)
+
+			connection := dial(t, server.GetAddress())
+			_, err = connection.Write([]byte("x"))
+			require.NoError(t, err)
+			<-handling
+
+			err = server.Stop()
+			if testCase.errMessage == "" {
+				require.NoError(t, err)
+				_, err = connection.Read(make([]byte, 1))
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrShutdownTimeout)
+			assert.EqualError(t, err, testCase.errMessage)
+			<-handlerCtxDone
+		})
+	}
+}
+
+func Test_Server_readError(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Settings{
+		Handler: echoHandler,
+		Address: stringPtr("127.0.0.1:0"),
+	})
+	require.NoError(t, err)
+
+	runError, err := server.Start(context.Background())
+	require.NoError(t, err)
+
+	_ = server.packetConn.Close()
+	err = <-runError
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	err = server.Stop()
+	require.NoError(t, err)
+}
@@ -0,0 +1,128 @@
+package udpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// PacketWriter is used by a handler to send
+// packets, for example a response to a request.
+type PacketWriter interface {
+	WriteTo(packet []byte, address net.Addr) (n int, err error)
+}
+
+// Handler handles a packet received by the UDP server from the
+// address given, and can respond using the packet writer.
+// The context is canceled if the handler does not return within
+// the shutdown timeout when the server stops.
+type Handler func(ctx context.Context, writer PacketWriter,
+	packet []byte, address net.Addr)
+
+// Settings is the settings for the UDP server service.
+type Settings struct {
+	// Handler is the packet handler to use.
+	// It must be set for settings validation to pass.
+	Handler Handler
+	// Name is the name of the server.
+	// It is used for the server `String` method
+	// and for logs if a logger is set.
+	// It defaults to the empty string.
+	Name *string
+	// Address is the listening address to use.
+	// It defaults to the empty string (random port).
+	Address *string
+	// Workers is the number of workers handling packets concurrently.
+	// It defaults to 16.
+	Workers int
+	// QueueSize is the number of packets received waiting for a worker.
+	// Once the queue is full, the server stops reading packets until
+	// a worker is available, and further packets may be dropped by
+	// the operating system. It defaults to the number of workers.
+	QueueSize int
+	// MaxPacketSize is the maximum size of a packet received, and
+	// larger packets are truncated. It defaults to 65535 bytes.
+	MaxPacketSize int
+	// ShutdownTimeout is the maximum duration to wait for handlers
+	// to handle packets received when stopping the server. Once it
+	// expires, the handlers context is canceled and the socket closed.
+	// It defaults to 3 seconds.
+	ShutdownTimeout time.Duration
+	// Logger is the logger to use to log on what
+	// address the server is listening.
+	// It defaults to a no-op logger.
+	Logger Infoer
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Name == nil {
+		s.Name = new(string)
+	}
+
+	if s.Address == nil {
+		s.Address = new(string)
+	}
+
+	if s.Workers == 0 {
+		const defaultWorkers = 16
+		s.Workers = defaultWorkers
+	}
+
+	if s.QueueSize == 0 {
+		s.QueueSize = s.Workers
+	}
+
+	if s.MaxPacketSize == 0 {
+		const defaultMaxPacketSize = 65535
+		s.MaxPacketSize = defaultMaxPacketSize
+	}
+
+	if s.ShutdownTimeout == 0 {
+		const defaultShutdownTimeout = 3 * time.Second
+		s.ShutdownTimeout = defaultShutdownTimeout
+	}
+
+	if s.Logger == nil {
+		s.Logger = new(noopLogger)
+	}
+}
+
+var (
+	ErrHandlerIsNil             = errors.New("handler is nil")
+	ErrListeningAddressNotValid = errors.New("listening address is not valid")
+	ErrWorkersNotValid          = errors.New("number of workers is not valid")
+	ErrQueueSizeNotValid        = errors.New("queue size is not valid")
+	ErrMaxPacketSizeNotValid    = errors.New("maximum packet size is not valid")
+	ErrShutdownTimeoutNotValid  = errors.New("shutdown timeout is not valid")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	if s.Handler == nil {
+		return fmt.Errorf("%w", ErrHandlerIsNil)
+	}
+
+	if *s.Address != "" {
+		_, err = net.ResolveUDPAddr("udp", *s.Address)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrListeningAddressNotValid, err)
+		}
+	}
+
+	switch {
+	case s.Workers < 1:
+		return fmt.Errorf("%w: %d", ErrWorkersNotValid, s.Workers)
+	case s.QueueSize < 0:
+		return fmt.Errorf("%w: %d", ErrQueueSizeNotValid, s.QueueSize)
+	case s.MaxPacketSize < 1:
+		return fmt.Errorf("%w: %d", ErrMaxPacketSizeNotValid, s.MaxPacketSize)
+	case s.ShutdownTimeout < 0:
+		return fmt.Errorf("%w: %s", ErrShutdownTimeoutNotValid, s.ShutdownTimeout)
+	}
+
+	return nil
+}
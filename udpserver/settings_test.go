@@ -0,0 +1,92 @@
+package udpserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Settings_SetDefaults(t *testing.T) {
+	t.Parallel()
+
+	settings := Settings{Workers: 4}
+	settings.SetDefaults()
+
+	assert.Equal(t, Settings{
+		Name:            stringPtr(""),
+		Address:         stringPtr(""),
+		Workers:         4,
+		QueueSize:       4,
+		MaxPacketSize:   65535,
+		ShutdownTimeout: 3 * time.Second,
+		Logger:          &noopLogger{},
+	}, settings)
+}
+
+func Test_Settings_Validate(t *testing.T) {
+	t.Parallel()
+
+	handler := func(context.Context, PacketWriter, []byte, net.Addr) {}
+
+	testCases := map[string]struct {
+		settings   Settings
+		errWrapped error
+		errMessage string
+	}{
+		"nil handler": {
+			errWrapped: ErrHandlerIsNil,
+			errMessage: "handler is nil",
+		},
+		"invalid address": {
+			settings: Settings{
+				Handler: handler,
+				Address: stringPtr("127.0.0.1:-1"),
+			},
+			errWrapped: ErrListeningAddressNotValid,
+			errMessage: "listening address is not valid: address -1: invalid port",
+		},
+		"no worker": {
+			settings: Settings{
+				Handler: handler,
+				Address: stringPtr(""),
+			},
+			errWrapped: ErrWorkersNotValid,
+			errMessage: "number of workers is not valid: 0",
+		},
+		"negative shutdown timeout": {
+			settings: Settings{
+				Handler:         handler,
+				Address:         stringPtr(""),
+				Workers:         1,
+				MaxPacketSize:   512,
+				ShutdownTimeout: -time.Second,
+			},
+			errWrapped: ErrShutdownTimeoutNotValid,
+			errMessage: "shutdown timeout is not valid: -1s",
+		},
+		"valid settings": {
+			settings: Settings{
+				Handler:       handler,
+				Address:       stringPtr(":0"),
+				Workers:       1,
+				MaxPacketSize: 512,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.settings.Validate()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
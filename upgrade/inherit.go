@@ -0,0 +1,65 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// environmentKey is the environment variable set for the upgraded
+// process, listing the names of the inherited listeners separated
+// by commas, in the order of their file descriptors.
+const environmentKey = "GOSERVICES_UPGRADE_LISTENERS"
+
+const (
+	// readyFD is the file descriptor of the write end of the
+	// readiness pipe in the upgraded process.
+	readyFD = 3
+	// listenersFDStart is the first file descriptor of the
+	// inherited listeners in the upgraded process.
+	listenersFDStart = 4
+)
+
+var ErrListenerNameNotValid = errors.New("listener name is not valid")
+
+// inherit returns the readiness pipe and listeners inherited
+// from a parent process, and unsets the environment variable
+// so it is not passed on to child processes.
+// The ready file returned is nil if there is no parent process.
+func inherit() (ready *os.File, listeners map[string]net.Listener, err error) {
+	listeners = make(map[string]net.Listener)
+	value, ok := os.LookupEnv(environmentKey)
+	if !ok {
+		return nil, listeners, nil
+	}
+	_ = os.Unsetenv(environmentKey)
+
+	var names []string
+	if value != "" {
+		names = strings.Split(value, ",")
+	}
+
+	for i, name := range names {
+		fd := listenersFDStart + i
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file)
+		_ = file.Close() // the listener uses a duplicate file descriptor
+		if err != nil {
+			for _, listener := range listeners {
+				_ = listener.Close()
+			}
+			return nil, nil, fmt.Errorf("creating listener %s from file descriptor %d: %w",
+				name, fd, err)
+		}
+		listeners[name] = listener
+	}
+
+	ready = os.NewFile(readyFD, "upgrade ready pipe")
+	return ready, listeners, nil
+}
+
+func listenerName(network, address string) string {
+	return network + ":" + address
+}
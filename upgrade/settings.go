@@ -0,0 +1,67 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Settings is the settings for the upgrader service.
+type Settings struct {
+	// Name is the name of the service.
+	// It is used for the service `String` method.
+	// It defaults to the empty string.
+	Name *string
+	// Path is the path of the binary to execute on upgrade.
+	// It defaults to the path of the current executable.
+	Path string
+	// Arguments are the arguments to pass to the upgraded process,
+	// excluding the program name.
+	// It defaults to the arguments of the current process.
+	Arguments []string
+	// ReadyTimeout is the maximum duration to wait for the upgraded
+	// process to signal it is ready, after which the upgraded process
+	// is killed and the upgrade fails.
+	// It defaults to one minute.
+	ReadyTimeout time.Duration
+}
+
+// SetDefaults sets the default values for the settings.
+func (s *Settings) SetDefaults() {
+	if s.Name == nil {
+		s.Name = new(string)
+	}
+
+	if s.Path == "" {
+		s.Path, _ = os.Executable()
+	}
+
+	if s.Arguments == nil {
+		s.Arguments = os.Args[1:]
+	}
+
+	const defaultReadyTimeout = time.Minute
+	if s.ReadyTimeout == 0 {
+		s.ReadyTimeout = defaultReadyTimeout
+	}
+}
+
+var (
+	ErrPathEmpty           = errors.New("path is empty")
+	ErrReadyTimeoutInvalid = errors.New("ready timeout is not valid")
+)
+
+// Validate validates the settings and returns an error
+// if any setting is not valid.
+func (s Settings) Validate() (err error) {
+	if s.Path == "" {
+		return fmt.Errorf("%w", ErrPathEmpty)
+	}
+
+	if s.ReadyTimeout < 0 {
+		return fmt.Errorf("%w: %s", ErrReadyTimeoutInvalid, s.ReadyTimeout)
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package upgrade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Settings_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		settings   Settings
+		errWrapped error
+		errMessage string
+	}{
+		"empty path": {
+			errWrapped: ErrPathEmpty,
+			errMessage: "path is empty",
+		},
+		"negative ready timeout": {
+			settings: Settings{
+				Path:         "/bin/program",
+				ReadyTimeout: -time.Second,
+			},
+			errWrapped: ErrReadyTimeoutInvalid,
+			errMessage: "ready timeout is not valid: -1s",
+		},
+		"valid settings": {
+			settings: Settings{
+				Path:         "/bin/program",
+				ReadyTimeout: time.Second,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.settings.Validate()
+
+			assert.ErrorIs(t, err, testCase.errWrapped)
+			if testCase.errWrapped != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+		})
+	}
+}
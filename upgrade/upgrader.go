@@ -0,0 +1,375 @@
+// Package upgrade implements a service to upgrade the program
+// without dropping connections, by re-executing its binary and
+// handing over its listeners to the upgraded process.
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qdm12/goservices"
+)
+
+var _ goservices.Service = (*Upgrader)(nil)
+
+// Upgrader is a service handing over its registered listeners to an
+// upgraded process. When upgrading, it re-executes the program binary
+// passing the listeners as inherited file descriptors, and waits for
+// the upgraded process to signal it is ready over a pipe. It then sends
+// a run error wrapping `goservices.ErrUpgraded`, so a parent service
+// management type such as a group stops all its services as usual.
+//
+// In the upgraded process, the upgrader signals its readiness to the
+// parent process when it is started, so it should be started after the
+// services using its listeners, for example last in a sequence.
+type Upgrader struct {
+	// Dependencies injected
+	settings Settings
+
+	// Internal fields
+	startStopMutex sync.Mutex
+	state          goservices.State
+	stateMutex     sync.RWMutex
+	upgradeMutex   sync.Mutex
+	hasParent      bool
+	ready          *os.File
+	inherited      map[string]net.Listener
+	names          []string
+	listeners      map[string]net.Listener
+	listenersMutex sync.Mutex
+	runError       chan error
+	stop           chan struct{}
+}
+
+// New creates a new upgrader service using the settings given,
+// inheriting the listeners of the parent process if the program
+// was started by an upgrade.
+func New(settings Settings) (upgrader *Upgrader, err error) {
+	settings.SetDefaults()
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	ready, inherited, err := inherit()
+	if err != nil {
+		return nil, fmt.Errorf("inheriting from parent process: %w", err)
+	}
+
+	return &Upgrader{
+		settings:  settings,
+		state:     goservices.StateStopped,
+		hasParent: ready != nil,
+		ready:     ready,
+		inherited: inherited,
+		listeners: make(map[string]net.Listener),
+	}, nil
+}
+
+func (u *Upgrader) String() string {
+	if *u.settings.Name == "" {
+		return "upgrader"
+	}
+	return *u.settings.Name + " upgrader"
+}
+
+// HasParent returns true if the program was started
+// by an upgrade from a parent process.
+func (u *Upgrader) HasParent() bool {
+	return u.hasParent
+}
+
+var (
+	ErrListenerAlreadyRegistered = errors.New("listener already registered")
+	ErrListenerNoFile            = errors.New("listener does not expose its file")
+)
+
+type filer interface {
+	File() (file *os.File, err error)
+}
+
+// Listen returns a listener for the network and address given,
+// inherited from the parent process if available, or created otherwise.
+// The listener is registered to be handed over on upgrade, and should be
+// served by another service, for example using the `Listeners` field of
+// the `httpserver` settings. Note the address should be the same across
+// upgrades for the listener to be inherited, for example "127.0.0.1:0"
+// gets a random port only in the first process.
+func (u *Upgrader) Listen(ctx context.Context, network, address string) (
+	listener net.Listener, err error) {
+	name := listenerName(network, address)
+
+	u.listenersMutex.Lock()
+	defer u.listenersMutex.Unlock()
+
+	if _, ok := u.listeners[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrListenerAlreadyRegistered, name)
+	}
+
+	listener, ok := u.inherited[name]
+	if ok {
+		delete(u.inherited, name)
+	} else {
+		listenConfig := net.ListenConfig{}
+		listener, err = listenConfig.Listen(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	u.names = append(u.names, name)
+	u.listeners[name] = listener
+	return listener, nil
+}
+
+// AddListener registers an existing listener with a unique name
+// to be handed over on upgrade. The listener must implement a
+// `File() (*os.File, error)` method, such as TCP and unix listeners.
+// An upgraded process can obtain the listener back with `Inherited`.
+func (u *Upgrader) AddListener(name string, listener net.Listener) (err error) {
+	if name == "" || strings.Contains(name, ",") {
+		return fmt.Errorf("%w: %q", ErrListenerNameNotValid, name)
+	} else if _, ok := listener.(filer); !ok {
+		return fmt.Errorf("%w: %T", ErrListenerNoFile, listener)
+	}
+
+	u.listenersMutex.Lock()
+	defer u.listenersMutex.Unlock()
+	if _, ok := u.listeners[name]; ok {
+		return fmt.Errorf("%w: %s", ErrListenerAlreadyRegistered, name)
+	}
+	u.names = append(u.names, name)
+	u.listeners[name] = listener
+	return nil
+}
+
+// Inherited returns the listener inherited from the parent process
+// with the name given to `AddListener`, or nil if there is no such
+// listener. The listener returned is no longer registered, and should
+// be registered again with `AddListener` to be handed over on upgrade.
+func (u *Upgrader) Inherited(name string) (listener net.Listener) {
+	u.listenersMutex.Lock()
+	defer u.listenersMutex.Unlock()
+	listener = u.inherited[name]
+	delete(u.inherited, name)
+	return listener
+}
+
+// Start signals to the parent process, if any, that the
+// upgraded process is ready, and closes inherited listeners
+// not obtained with `Listen` or `Inherited`.
+func (u *Upgrader) Start(_ context.Context) (runError <-chan error, err error) {
+	u.startStopMutex.Lock()
+	defer u.startStopMutex.Unlock()
+
+	u.stateMutex.Lock()
+	if u.state == goservices.StateRunning {
+		u.stateMutex.Unlock()
+		return nil, fmt.Errorf("%s: %w", u, goservices.ErrAlreadyStarted)
+	}
+	u.state = goservices.StateStarting
+	u.stateMutex.Unlock()
+
+	if u.ready != nil {
+		_, err = u.ready.Write([]byte{1})
+		_ = u.ready.Close()
+		u.ready = nil
+		if err != nil {
+			u.stateMutex.Lock()
+			u.state = goservices.StateStopped
+			u.stateMutex.Unlock()
+			return nil, fmt.Errorf("signaling readiness to parent process: %w", err)
+		}
+	}
+
+	u.listenersMutex.Lock()
+	for name, listener := range u.inherited {
+		_ = listener.Close()
+		delete(u.inherited, name)
+	}
+	u.listenersMutex.Unlock()
+
+	u.runError = make(chan error)
+	u.stop = make(chan struct{})
+	u.stateMutex.Lock()
+	u.state = goservices.StateRunning
+	u.stateMutex.Unlock()
+
+	return u.runError, nil
+}
+
+var (
+	ErrNotRunning        = errors.New("not running")
+	ErrUpgradeInProgress = errors.New("upgrade already in progress")
+	ErrUpgradedNotReady  = errors.New("upgraded process exited before being ready")
+	ErrUpgradeTimeout    = errors.New("upgraded process not ready in time")
+	ErrUpgraderStopped   = errors.New("upgrader stopped")
+)
+
+// Upgrade re-executes the program binary, handing over the registered
+// listeners, and waits for the upgraded process to be ready. If the
+// upgraded process fails to be ready before the ready timeout, the context
+// is canceled or the upgrader is stopped, the upgraded process is killed
+// and an error is returned. Otherwise, the upgrader sends a run error
+// wrapping `goservices.ErrUpgraded` and Upgrade returns nil. It can be
+// called, for example, from a `signals` service callback on SIGHUP.
+func (u *Upgrader) Upgrade(ctx context.Context) (err error) {
+	if !u.upgradeMutex.TryLock() {
+		return fmt.Errorf("%w", ErrUpgradeInProgress)
+	}
+	defer u.upgradeMutex.Unlock()
+
+	u.stateMutex.RLock()
+	state, stop := u.state, u.stop
+	u.stateMutex.RUnlock()
+	if state != goservices.StateRunning {
+		return fmt.Errorf("%s: %w", u, ErrNotRunning)
+	}
+
+	files, names, err := u.listenerFiles()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("creating readiness pipe: %w", err)
+	}
+	defer readyReader.Close()
+
+	cmd := exec.Command(u.settings.Path, u.settings.Arguments...) //nolint:gosec
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = slices.DeleteFunc(os.Environ(), func(variable string) bool {
+		return strings.HasPrefix(variable, environmentKey+"=")
+	})
+	cmd.Env = append(cmd.Env, environmentKey+"="+strings.Join(names, ","))
+	cmd.ExtraFiles = append([]*os.File{readyWriter}, files...)
+	err = cmd.Start()
+	_ = readyWriter.Close()
+	if err != nil {
+		return fmt.Errorf("starting upgraded process: %w", err)
+	}
+
+	readResult := make(chan error, 1)
+	go func() {
+		_, err := readyReader.Read(make([]byte, 1))
+		readResult <- err
+	}()
+
+	timer := time.NewTimer(u.settings.ReadyTimeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-readResult:
+		if err != nil {
+			// The readiness pipe is closed by the
+			// upgraded process exiting.
+			exitErr := cmd.Wait()
+			if exitErr == nil {
+				return fmt.Errorf("%w", ErrUpgradedNotReady)
+			}
+			return fmt.Errorf("%w: %w", ErrUpgradedNotReady, exitErr)
+		}
+	case <-timer.C:
+		err = fmt.Errorf("%w: after %s", ErrUpgradeTimeout, u.settings.ReadyTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-stop:
+		err = fmt.Errorf("%w", ErrUpgraderStopped)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+
+	u.stateMutex.Lock()
+	if u.state != goservices.StateRunning {
+		// the upgrader is stopping
+		u.stateMutex.Unlock()
+		return nil
+	}
+	u.state = goservices.StateCrashed
+	u.stateMutex.Unlock()
+
+	select {
+	case u.runError <- fmt.Errorf("%w: process %d is ready", goservices.ErrUpgraded, pid):
+	case <-stop:
+	}
+	return nil
+}
+
+func (u *Upgrader) listenerFiles() (files []*os.File, names []string, err error) {
+	u.listenersMutex.Lock()
+	defer u.listenersMutex.Unlock()
+
+	files = make([]*os.File, 0, len(u.names))
+	for _, name := range u.names {
+		listener := u.listeners[name]
+		if unixListener, ok := listener.(*net.UnixListener); ok {
+			// Keep the socket file for the upgraded process.
+			unixListener.SetUnlinkOnClose(false)
+		}
+		file, err := listener.(filer).File()
+		if err != nil {
+			for _, file := range files {
+				_ = file.Close()
+			}
+			return nil, nil, fmt.Errorf("getting file of listener %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+	return files, slices.Clone(u.names), nil
+}
+
+// Stop stops the upgrader, and cancels an eventual upgrade
+// in progress, killing the upgraded process.
+func (u *Upgrader) Stop() (err error) {
+	u.startStopMutex.Lock()
+	defer u.startStopMutex.Unlock()
+
+	u.stateMutex.Lock()
+	switch u.state {
+	case goservices.StateRunning: // continue stopping the service
+	case goservices.StateCrashed: // upgraded process is ready
+		u.state = goservices.StateStopped
+		u.stateMutex.Unlock()
+		close(u.stop)
+		return nil
+	case goservices.StateStopped:
+		u.stateMutex.Unlock()
+		return fmt.Errorf("%s: %w", u, goservices.ErrAlreadyStopped)
+	case goservices.StateStarting, goservices.StateStopping:
+		u.stateMutex.Unlock()
+		panic("bad implementation code: this code path should be unreachable")
+	}
+	u.state = goservices.StateStopping
+	u.stateMutex.Unlock()
+
+	close(u.stop)
+	// Wait for an eventual upgrade in progress to complete.
+	u.upgradeMutex.Lock()
+	u.upgradeMutex.Unlock() //nolint:staticcheck
+	u.stateMutex.Lock()
+	u.state = goservices.StateStopped
+	u.stateMutex.Unlock()
+	return nil
+}
@@ -0,0 +1,151 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qdm12/goservices"
+	"github.com/qdm12/goservices/httpserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperKey is the environment variable making the test
+// binary run as a helper upgraded process, see `runHelper`.
+const helperKey = "GOSERVICES_UPGRADE_TEST_HELPER"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(helperKey) {
+	case "":
+		os.Exit(m.Run())
+	case "fail":
+		os.Exit(1)
+	default:
+		os.Exit(runHelper())
+	}
+}
+
+// runHelper runs as the upgraded process, serving
+// a single request on the inherited listener.
+func runHelper() (exitCode int) {
+	upgrader, err := New(Settings{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	} else if !upgrader.HasParent() {
+		fmt.Fprintln(os.Stderr, "no parent process")
+		return 1
+	}
+
+	listener, err := upgrader.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	served := make(chan struct{})
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("upgraded"))
+			close(served)
+		}),
+		ReadHeaderTimeout: time.Second,
+	}
+	go func() { _ = server.Serve(listener) }()
+
+	_, err = upgrader.Start(context.Background())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	select {
+	case <-served:
+	case <-time.After(10 * time.Second):
+	}
+	_ = server.Shutdown(context.Background())
+	_ = upgrader.Stop()
+	return 0
+}
+
+//nolint:paralleltest // environment variables are process wide
+func Test_Upgrader(t *testing.T) {
+	t.Setenv(helperKey, "serve")
+
+	upgrader, err := New(Settings{ReadyTimeout: 10 * time.Second})
+	require.NoError(t, err)
+	assert.False(t, upgrader.HasParent())
+
+	listener, err := upgrader.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+
+	server, err := httpserver.New(httpserver.Settings{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("original"))
+		}),
+		Listeners: []net.Listener{listener},
+	})
+	require.NoError(t, err)
+	_, err = server.Start(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "original", get(t, address))
+
+	runError, err := upgrader.Start(context.Background())
+	require.NoError(t, err)
+
+	upgradeDone := make(chan error)
+	go func() {
+		upgradeDone <- upgrader.Upgrade(context.Background())
+	}()
+
+	err = <-runError
+	require.ErrorIs(t, err, goservices.ErrUpgraded)
+	require.NoError(t, <-upgradeDone)
+
+	// Stop the original server, and requests are then served
+	// by the upgraded process on the inherited listener.
+	require.NoError(t, server.Stop())
+	require.NoError(t, upgrader.Stop())
+	assert.Equal(t, "upgraded", get(t, address))
+}
+
+//nolint:paralleltest // environment variables are process wide
+func Test_Upgrader_Upgrade_notReady(t *testing.T) {
+	t.Setenv(helperKey, "fail")
+
+	upgrader, err := New(Settings{})
+	require.NoError(t, err)
+
+	err = upgrader.Upgrade(context.Background())
+	assert.ErrorIs(t, err, ErrNotRunning)
+
+	_, err = upgrader.Start(context.Background())
+	require.NoError(t, err)
+
+	err = upgrader.Upgrade(context.Background())
+	assert.ErrorIs(t, err, ErrUpgradedNotReady)
+	assert.EqualError(t, err, "upgraded process exited before being ready: exit status 1")
+
+	require.NoError(t, upgrader.Stop())
+}
+
+func get(t *testing.T, address string) (body string) {
+	t.Helper()
+	client := &http.Client{Timeout: 5 * time.Second}
+	request, err := http.NewRequestWithContext(context.Background(),
+		http.MethodGet, "http://"+address, nil)
+	require.NoError(t, err)
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	return string(data)
+}
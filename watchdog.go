@@ -0,0 +1,157 @@
+package goservices
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+var _ Service = (*Watchdog)(nil)
+
+// WatchdogSink is the interface required to receive
+// slow and hanging service start and stop events.
+// Its methods should be thread safe.
+type WatchdogSink interface {
+	// OnSlow is called when a service start or stop is
+	// still in progress after the soft timeout.
+	// The `phase` argument is either "start" or "stop".
+	OnSlow(service, phase string, elapsed time.Duration)
+	// OnHang is called when a service start or stop is
+	// still in progress after the hard timeout, with the
+	// stacks of the goroutines labeled for the service, or
+	// the stacks of all goroutines if none is labeled.
+	// The `phase` argument is either "start" or "stop".
+	OnHang(service, phase string, elapsed time.Duration, stacks []byte)
+}
+
+// Watchdog wraps a service and reports its start and stop calls
+// taking longer than a soft timeout and a hard timeout to a sink.
+// Past the hard timeout, the stacks of the goroutines labeled with
+// the service path are captured and passed to the sink.
+// To that end, the watchdog enables profiler labels for the service
+// it wraps, see `ProfilerLabelService`.
+type Watchdog struct {
+	forwarder
+	softTimeout    time.Duration
+	hardTimeout    time.Duration
+	sink           WatchdogSink
+	startStopMutex sync.Mutex
+	profiler       profiler
+}
+
+// NewWatchdog creates a new watchdog given the settings.
+// It returns an error if any of the settings is not valid.
+func NewWatchdog(settings WatchdogSettings) (watchdog *Watchdog, err error) {
+	settings.setDefaults()
+
+	err = settings.validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating settings: %w", err)
+	}
+
+	return newWatchdog(settings.Service, settings), nil
+}
+
+func newWatchdog(service Service, settings WatchdogSettings) *Watchdog {
+	return &Watchdog{
+		forwarder:   forwarder{service: service},
+		softTimeout: settings.SoftTimeout,
+		hardTimeout: settings.HardTimeout,
+		sink:        settings.Sink,
+	}
+}
+
+// wrapWithWatchdogs returns watchdogs wrapping each service given,
+// in the same order, using the watchdog settings given.
+// The settings service field is ignored.
+func wrapWithWatchdogs(services []Service,
+	settings WatchdogSettings) (watchdogs []Service) {
+	watchdogs = make([]Service, len(services))
+	for i, service := range services {
+		watchdogs[i] = newWatchdog(service, settings)
+	}
+	return watchdogs
+}
+
+func (w *Watchdog) String() string {
+	return w.service.String()
+}
+
+// Start starts the underlying service, reporting
+// to the sink if it takes too long to start.
+func (w *Watchdog) Start(ctx context.Context) (runError <-chan error, startErr error) {
+	w.startStopMutex.Lock()
+	defer w.startStopMutex.Unlock()
+
+	// The watchdog is transparent and uses the underlying service
+	// name, so the underlying service path is the watchdog path.
+	w.profiler = newProfiler(ctx, true, w.service.String())
+	w.watch(ctx, phaseStart, func(ctx context.Context) {
+		runError, startErr = w.service.Start(ctx)
+	})
+	return runError, startErr
+}
+
+// Stop stops the underlying service, reporting
+// to the sink if it takes too long to stop.
+func (w *Watchdog) Stop() (err error) {
+	w.startStopMutex.Lock()
+	defer w.startStopMutex.Unlock()
+
+	if !w.profiler.enabled {
+		// Stop called before Start
+		w.profiler = newProfiler(context.Background(), true, w.service.String())
+	}
+	w.watch(context.Background(), phaseStop, func(context.Context) {
+		err = w.service.Stop()
+	})
+	return err
+}
+
+// watch runs `f` with the profiler labels of the service,
+// and reports to the sink if it takes longer than the soft
+// timeout or the hard timeout to complete.
+func (w *Watchdog) watch(ctx context.Context, phase string,
+	f func(ctx context.Context)) {
+	done := make(chan struct{})
+	monitorDone := make(chan struct{})
+	go w.monitor(phase, done, monitorDone)
+	w.profiler.do(ctx, w.profiler.path, phase, f)
+	close(done)
+	<-monitorDone
+}
+
+func (w *Watchdog) monitor(phase string, done <-chan struct{},
+	monitorDone chan<- struct{}) {
+	defer close(monitorDone)
+	startTime := time.Now()
+	// Remove labels inherited from the calling goroutine so this
+	// goroutine does not appear in the service goroutines dump.
+	pprof.SetGoroutineLabels(context.Background())
+
+	var softTimeout, hardTimeout <-chan time.Time
+	if w.softTimeout > 0 {
+		timer := time.NewTimer(w.softTimeout)
+		defer timer.Stop()
+		softTimeout = timer.C
+	}
+	if w.hardTimeout > 0 {
+		timer := time.NewTimer(w.hardTimeout)
+		defer timer.Stop()
+		hardTimeout = timer.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-softTimeout:
+			w.sink.OnSlow(w.profiler.path, phase, time.Since(startTime))
+		case <-hardTimeout:
+			stacks := goroutinesDump(w.profiler.path)
+			w.sink.OnHang(w.profiler.path, phase, time.Since(startTime), stacks)
+		}
+	}
+}
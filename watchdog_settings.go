@@ -0,0 +1,65 @@
+package goservices
+
+import (
+	"fmt"
+	"time"
+)
+
+// WatchdogSettings contains settings for a watchdog.
+type WatchdogSettings struct {
+	// Service is the service to watch.
+	// It must be set for settings validation to succeed,
+	// except when used as composite settings field, where
+	// it is ignored and set to each service of the composite.
+	Service Service
+	// SoftTimeout is the duration after which a service
+	// start or stop still in progress is reported to the sink
+	// `OnSlow` method. It defaults to 10 seconds if left unset,
+	// and can be set to a negative value to disable it.
+	SoftTimeout time.Duration
+	// HardTimeout is the duration after which a service
+	// start or stop still in progress is reported to the sink
+	// `OnHang` method together with a dump of its goroutines
+	// stacks. It defaults to 30 seconds if left unset, and can be
+	// set to a negative value to disable it.
+	HardTimeout time.Duration
+	// Sink is the sink receiving slow and hanging service events.
+	// It must be set for settings validation to succeed.
+	Sink WatchdogSink
+}
+
+// setDefaults sets the defaults for the watchdog settings.
+func (w *WatchdogSettings) setDefaults() {
+	if w.SoftTimeout == 0 {
+		const defaultSoftTimeout = 10 * time.Second
+		w.SoftTimeout = defaultSoftTimeout
+	}
+
+	if w.HardTimeout == 0 {
+		const defaultHardTimeout = 30 * time.Second
+		w.HardTimeout = defaultHardTimeout
+	}
+}
+
+// validate validates the watchdog settings.
+func (w WatchdogSettings) validate() (err error) {
+	if w.Service == nil {
+		return fmt.Errorf("%w", ErrNoService)
+	}
+	return w.validateWatch()
+}
+
+// validateWatch validates the watchdog settings except
+// for the service, which is not set when the settings are
+// used as a composite settings field.
+func (w WatchdogSettings) validateWatch() (err error) {
+	switch {
+	case w.Sink == nil:
+		return fmt.Errorf("%w", ErrWatchdogSinkIsNil)
+	case w.SoftTimeout > 0 && w.HardTimeout > 0 && w.SoftTimeout >= w.HardTimeout:
+		return fmt.Errorf("%w: soft timeout %s must be less than hard timeout %s",
+			ErrWatchdogTimeoutsNotValid, w.SoftTimeout, w.HardTimeout)
+	}
+
+	return nil
+}
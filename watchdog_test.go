@@ -0,0 +1,197 @@
+package goservices
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testWatchdogSink struct {
+	mutex  sync.Mutex
+	events []string
+	stacks []byte
+}
+
+func (s *testWatchdogSink) OnSlow(service, phase string, _ time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, "slow "+phase+" "+service)
+}
+
+func (s *testWatchdogSink) OnHang(service, phase string, _ time.Duration, stacks []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, "hang "+phase+" "+service)
+	s.stacks = stacks
+}
+
+func Test_NewWatchdog(t *testing.T) {
+	t.Parallel()
+
+	service := NewRunWrapper("A", nil)
+	sink := &testWatchdogSink{}
+
+	testCases := map[string]struct {
+		settings    WatchdogSettings
+		watchdog    *Watchdog
+		errSentinel error
+		errMessage  string
+	}{
+		"no service": {
+			errSentinel: ErrNoService,
+			errMessage:  "validating settings: no service specified",
+		},
+		"no sink": {
+			settings:    WatchdogSettings{Service: service},
+			errSentinel: ErrWatchdogSinkIsNil,
+			errMessage:  "validating settings: watchdog sink is nil",
+		},
+		"soft timeout greater than hard timeout": {
+			settings: WatchdogSettings{
+				Service:     service,
+				Sink:        sink,
+				SoftTimeout: time.Minute,
+			},
+			errSentinel: ErrWatchdogTimeoutsNotValid,
+			errMessage: "validating settings: watchdog timeouts are not valid: " +
+				"soft timeout 1m0s must be less than hard timeout 30s",
+		},
+		"default timeouts": {
+			settings: WatchdogSettings{
+				Service: service,
+				Sink:    sink,
+			},
+			watchdog: &Watchdog{
+				forwarder:   forwarder{service: service},
+				softTimeout: 10 * time.Second,
+				hardTimeout: 30 * time.Second,
+				sink:        sink,
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			watchdog, err := NewWatchdog(testCase.settings)
+
+			assert.ErrorIs(t, err, testCase.errSentinel)
+			if testCase.errSentinel != nil {
+				assert.EqualError(t, err, testCase.errMessage)
+			}
+			assert.Equal(t, testCase.watchdog, watchdog)
+		})
+	}
+}
+
+// testNonComparableService is a service with a non comparable
+// dynamic type, which cannot be used as a map key.
+type testNonComparableService struct {
+	*RunWrapper
+	_ []string
+}
+
+func Test_wrapWithWatchdogs(t *testing.T) {
+	t.Parallel()
+
+	services := []Service{
+		NewRunWrapper("A", nil),
+		testNonComparableService{RunWrapper: NewRunWrapper("B", nil)},
+	}
+	settings := WatchdogSettings{
+		SoftTimeout: time.Second,
+		HardTimeout: time.Minute,
+		Sink:        &testWatchdogSink{},
+	}
+
+	watchdogs := wrapWithWatchdogs(services, settings)
+
+	expected := []Service{
+		newWatchdog(services[0], settings),
+		newWatchdog(services[1], settings),
+	}
+	assert.Equal(t, expected, watchdogs)
+}
+
+func Test_Watchdog(t *testing.T) {
+	t.Parallel()
+
+	const stopDuration = 200 * time.Millisecond
+	service := NewRunWrapper("A", func(ctx context.Context,
+		ready chan<- struct{}, _, stopError chan<- error) {
+		close(ready)
+		<-ctx.Done()
+		time.Sleep(stopDuration)
+		close(stopError)
+	})
+
+	sink := &testWatchdogSink{}
+	group, err := NewGroup(GroupSettings{
+		Name:     "g",
+		Services: []Service{service},
+		Watchdog: &WatchdogSettings{
+			SoftTimeout: stopDuration / 4,
+			HardTimeout: stopDuration / 2,
+			Sink:        sink,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = group.Start(context.Background())
+	require.NoError(t, err)
+
+	err = group.Stop()
+	require.NoError(t, err)
+
+	sink.mutex.Lock()
+	defer sink.mutex.Unlock()
+	assert.Equal(t, []string{"slow stop group g/A", "hang stop group g/A"}, sink.events)
+	assert.Contains(t, string(sink.stacks), `"goservices.service":"group g/A"`)
+	assert.NotContains(t, string(sink.stacks), "goroutine profile:")
+}
+
+func Test_filterGoroutineProfile(t *testing.T) {
+	t.Parallel()
+
+	profile := strings.Join([]string{
+		"goroutine profile: total 3",
+		"1 @ 0x1",
+		`# labels: {"goservices.phase":"run", "goservices.service":"a/b"}`,
+		"#\t0x1\tmain.f+0x1\tmain.go:1",
+		"",
+		"1 @ 0x2",
+		`# labels: {"goservices.phase":"run", "goservices.service":"a/bc"}`,
+		"#\t0x2\tmain.g+0x1\tmain.go:2",
+		"",
+		"1 @ 0x3",
+		"#\t0x3\tmain.h+0x1\tmain.go:3",
+		"",
+		"1 @ 0x4",
+		`# labels: {"goservices.phase":"stop", "goservices.service":"a/b/c"}`,
+		"#\t0x4\tmain.i+0x1\tmain.go:4",
+		"",
+	}, "\n")
+
+	filtered := filterGoroutineProfile([]byte(profile), "a/b")
+
+	expected := strings.Join([]string{
+		"1 @ 0x1",
+		`# labels: {"goservices.phase":"run", "goservices.service":"a/b"}`,
+		"#\t0x1\tmain.f+0x1\tmain.go:1",
+		"",
+		"1 @ 0x4",
+		`# labels: {"goservices.phase":"stop", "goservices.service":"a/b/c"}`,
+		"#\t0x4\tmain.i+0x1\tmain.go:4",
+		"",
+	}, "\n")
+	assert.Equal(t, expected, string(filtered))
+
+	filtered = filterGoroutineProfile([]byte(profile), "x")
+	assert.Nil(t, filtered)
+}